@@ -0,0 +1,331 @@
+package endee
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Adaptive batching defaults.
+const (
+	DefaultMaxBatchBytes  = 4 << 20         // Upper bound on the encoded size of one upsert request
+	DefaultMaxBatchItems  = 100             // Upper bound on the number of vectors in one upsert request
+	DefaultTargetLatency  = 2 * time.Second // Responses slower than this shrink concurrency
+	retryBaseBackoff      = 100 * time.Millisecond
+	retryMaxBackoff       = 5 * time.Second
+	aimdDecreaseFactor    = 0.5 // Multiplicative decrease on 429/5xx
+	aimdSlowDecreaseRatio = 0.8 // Gentler decrease when only latency exceeds the target
+)
+
+// AdaptiveBatchConfig bounds how concurrent upserts size requests and scale concurrency.
+// Zero fields take their defaults.
+//
+// Sub-batches are cut by encoded msgpack size as well as item count, so a batch of
+// 8000-dim vectors with large metadata is split finer than one of 384-dim vectors.
+// Concurrency follows AIMD: it starts at MinConcurrency and doubles every round trip
+// until the first 429/5xx or slow response, then grows by roughly one request per
+// round trip while responses are fast and is cut back when the server answers 429/5xx
+// or latency exceeds TargetLatency. Each call enforces its own bounds; the limit it
+// learned is kept on the Index and seeds the next call.
+type AdaptiveBatchConfig struct {
+	MinConcurrency int           // Lower bound on in-flight requests (default 1)
+	MaxConcurrency int           // Upper bound on in-flight requests (default runtime.NumCPU())
+	MaxBatchBytes  int           // Maximum encoded bytes per request (default DefaultMaxBatchBytes)
+	MaxBatchItems  int           // Maximum vectors per request (default DefaultMaxBatchItems)
+	TargetLatency  time.Duration // Latency above which concurrency is reduced (default DefaultTargetLatency)
+	MaxRetries     int           // Retries for a sub-batch rejected with 429/5xx (default SessionMaxRetries)
+}
+
+// withDefaults fills zero fields with their defaults.
+func (c AdaptiveBatchConfig) withDefaults() AdaptiveBatchConfig {
+	if c.MinConcurrency <= 0 {
+		c.MinConcurrency = 1
+	}
+	if c.MaxConcurrency <= 0 {
+		c.MaxConcurrency = runtime.NumCPU()
+	}
+	if c.MaxConcurrency < c.MinConcurrency {
+		c.MaxConcurrency = c.MinConcurrency
+	}
+	if c.MaxBatchBytes <= 0 {
+		c.MaxBatchBytes = DefaultMaxBatchBytes
+	}
+	if c.MaxBatchItems <= 0 {
+		c.MaxBatchItems = DefaultMaxBatchItems
+	}
+	if c.MaxBatchItems > MaxVectorsPerBatch {
+		c.MaxBatchItems = MaxVectorsPerBatch
+	}
+	if c.TargetLatency <= 0 {
+		c.TargetLatency = DefaultTargetLatency
+	}
+	if c.MaxRetries <= 0 {
+		c.MaxRetries = SessionMaxRetries
+	}
+
+	return c
+}
+
+// WithAdaptiveBatching overrides the bounds used by the adaptive batcher.
+func WithAdaptiveBatching(config AdaptiveBatchConfig) UpsertOption {
+	return func(cfg *upsertConfig) {
+		cfg.adaptive = config
+	}
+}
+
+// encodedBatch is a group of individually encoded vector tuples sent as one request.
+type encodedBatch struct {
+	items [][]byte
+	size  int
+}
+
+// newEncodedBatch wraps items as a single batch.
+func newEncodedBatch(items [][]byte) encodedBatch {
+	batch := encodedBatch{items: items}
+	for _, item := range items {
+		batch.size += len(item)
+	}
+
+	return batch
+}
+
+// packEncodedBatches groups encoded items into batches bounded by maxBytes and maxItems.
+// An item larger than maxBytes is sent on its own.
+func packEncodedBatches(encoded [][]byte, maxBytes, maxItems int) []encodedBatch {
+	var batches []encodedBatch
+	var current encodedBatch
+
+	for _, item := range encoded {
+		if len(current.items) > 0 && (current.size+len(item) > maxBytes || len(current.items) >= maxItems) {
+			batches = append(batches, current)
+			current = encodedBatch{}
+		}
+		current.items = append(current.items, item)
+		current.size += len(item)
+	}
+	if len(current.items) > 0 {
+		batches = append(batches, current)
+	}
+
+	return batches
+}
+
+// body assembles the msgpack array of the batch's pre-encoded items.
+func (b encodedBatch) body() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Grow(b.size + 5)
+
+	if err := msgpack.NewEncoder(&buf).EncodeArrayLen(len(b.items)); err != nil {
+		return nil, fmt.Errorf("failed to encode batch header: %w", err)
+	}
+	for _, item := range b.items {
+		buf.Write(item)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// sendAdaptiveBatch sends one sub-batch, retrying retryable failures with backoff.
+// The caller must hold a limiter slot; it is released here. A nil limiter sends without one.
+func (idx *Index) sendAdaptiveBatch(ctx context.Context, batch encodedBatch, limiter *aimdLimiter, batchCfg AdaptiveBatchConfig, cfg *upsertConfig) error {
+	n := len(batch.items)
+
+	body, err := batch.body()
+	if err != nil {
+		limiter.release(0, false)
+		cfg.progress.failed(n)
+
+		return err
+	}
+
+	cfg.progress.submitted(n, len(body))
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			cfg.progress.sent(len(body))
+		}
+
+		start := time.Now()
+		err := idx.sendUpsertBody(ctx, body)
+		retryable := isRetryableError(err)
+		limiter.release(time.Since(start), retryable)
+
+		if err == nil {
+			cfg.progress.acknowledged(n)
+
+			return nil
+		}
+		if !retryable || attempt >= batchCfg.MaxRetries {
+			cfg.progress.failed(n)
+
+			return err
+		}
+
+		cfg.progress.retried(n)
+		if err := sleepContext(ctx, retryBackoff(attempt)); err != nil {
+			cfg.progress.failed(n)
+
+			return err
+		}
+		if err := limiter.acquire(ctx); err != nil {
+			cfg.progress.failed(n)
+
+			return err
+		}
+	}
+}
+
+// retryBackoff returns the jittered exponential delay before retry number attempt.
+func retryBackoff(attempt int) time.Duration {
+	backoff := retryBaseBackoff << attempt
+	if backoff > retryMaxBackoff || backoff <= 0 {
+		backoff = retryMaxBackoff
+	}
+
+	return backoff/2 + rand.N(backoff/2+1)
+}
+
+// sleepContext waits for d or until ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("upsert cancelled: %w", ctx.Err())
+	}
+}
+
+// aimdLimiter bounds in-flight requests with an additive-increase/multiplicative-decrease limit.
+type aimdLimiter struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	limit     float64
+	slowStart bool // Grow by one per success until the first decrease
+	inflight  int
+	config    AdaptiveBatchConfig
+}
+
+// newAIMDLimiter creates a limiter seeded from a previously learned limit.
+// A zero learned limit starts at the configured minimum in slow start.
+func newAIMDLimiter(config AdaptiveBatchConfig, learned float64) *aimdLimiter {
+	l := &aimdLimiter{
+		limit:     float64(config.MinConcurrency),
+		slowStart: true,
+		config:    config,
+	}
+	if learned > 0 {
+		l.limit = clampFloat(learned, float64(config.MinConcurrency), float64(config.MaxConcurrency))
+		l.slowStart = false
+	}
+	l.cond = sync.NewCond(&l.mu)
+
+	return l
+}
+
+// current returns the limit learned so far.
+func (l *aimdLimiter) current() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.limit
+}
+
+// acquire blocks until a request slot is free or ctx is done. A nil limiter never blocks.
+func (l *aimdLimiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		l.mu.Lock()
+		l.cond.Broadcast()
+		l.mu.Unlock()
+	})
+	defer stop()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for l.inflight >= int(l.limit) {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("upsert cancelled: %w", err)
+		}
+		l.cond.Wait()
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("upsert cancelled: %w", err)
+	}
+
+	l.inflight++
+
+	return nil
+}
+
+// release frees a slot and adjusts the limit from the observed latency and overload signal.
+// A zero latency releases the slot without adjusting the limit.
+func (l *aimdLimiter) release(latency time.Duration, overloaded bool) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inflight--
+
+	switch {
+	case overloaded:
+		l.limit *= aimdDecreaseFactor
+		l.slowStart = false
+	case latency > l.config.TargetLatency:
+		l.limit *= aimdSlowDecreaseRatio
+		l.slowStart = false
+	case latency > 0 && l.slowStart:
+		l.limit++
+	case latency > 0:
+		l.limit += 1 / l.limit
+	}
+	l.limit = clampFloat(l.limit, float64(l.config.MinConcurrency), float64(l.config.MaxConcurrency))
+
+	l.cond.Broadcast()
+}
+
+// clampFloat limits v to [lo, hi].
+func clampFloat(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+
+	return v
+}
+
+// concurrencyLimiter returns a limiter bounded by config for one upsert call,
+// seeded from the limit the index learned in earlier calls.
+func (idx *Index) concurrencyLimiter(config AdaptiveBatchConfig) *aimdLimiter {
+	idx.limiterMu.Lock()
+	defer idx.limiterMu.Unlock()
+
+	return newAIMDLimiter(config, idx.learnedLimit)
+}
+
+// rememberLimit stores the limit a finished call learned so the next call starts from it.
+func (idx *Index) rememberLimit(limiter *aimdLimiter) {
+	limit := limiter.current()
+
+	idx.limiterMu.Lock()
+	defer idx.limiterMu.Unlock()
+
+	idx.learnedLimit = limit
+}
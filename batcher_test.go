@@ -0,0 +1,46 @@
+package endee
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+func TestUpsertCancelledCountsUnsentBatchesAsFailed(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer srv.Close()
+
+	idx := &Index{Name: "docs", URL: srv.URL, Dimension: 2, SpaceType: L2}
+
+	items := make([]VectorItem, 30)
+	for i := range items {
+		items[i] = VectorItem{ID: "v" + strconv.Itoa(i), Vector: []float32{float32(i), 1}}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var last ProgressEvent
+	err := idx.UpsertWithContext(ctx, items,
+		WithAdaptiveBatching(AdaptiveBatchConfig{MaxBatchItems: 10}),
+		WithProgress(func(e ProgressEvent) { last = e }),
+	)
+	if err == nil {
+		t.Fatal("upsert with a cancelled context succeeded")
+	}
+	if !last.Done {
+		t.Fatal("no final progress event")
+	}
+	if last.Acknowledged+last.Failed != last.Total {
+		t.Fatalf("final event acknowledged %d + failed %d, want total %d", last.Acknowledged, last.Failed, last.Total)
+	}
+	if requests.Load() != 0 {
+		t.Fatalf("server received %d requests, want 0", requests.Load())
+	}
+}
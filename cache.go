@@ -0,0 +1,334 @@
+package endee
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+)
+
+// QueryCache stores search results for an Index. Implementations must be safe for
+// concurrent use. Results passed to Set and returned from Get are owned by the cache;
+// the Index copies them on the way in and out.
+type QueryCache interface {
+	Get(key string) ([]QueryResult, bool)
+	Set(key string, results []QueryResult)
+	Purge() // Drop every entry
+}
+
+// CacheStats reports query cache activity.
+type CacheStats struct {
+	Hits          uint64 // Queries answered from the cache
+	Misses        uint64 // Queries sent to the server
+	Invalidations uint64 // Purges caused by writes through the Index
+	Evictions     uint64 // Entries dropped for size or age (LRUQueryCache only)
+	Entries       int    // Entries currently cached (LRUQueryCache only)
+	Bytes         int64  // Approximate size of cached entries (LRUQueryCache only)
+}
+
+// SetQueryCache enables caching of search results on this handle; nil disables it.
+//
+// Upserts, deletes, filter updates and rebuilds made through this handle purge the cache.
+// Writes made through other handles or clients are not seen, so pair the cache with a TTL.
+func (idx *Index) SetQueryCache(cache QueryCache) {
+	idx.cacheMu.Lock()
+	defer idx.cacheMu.Unlock()
+
+	idx.cache = cache
+}
+
+// CacheStats returns query cache counters for this handle.
+func (idx *Index) CacheStats() CacheStats {
+	idx.cacheMu.RLock()
+	cache := idx.cache
+	idx.cacheMu.RUnlock()
+
+	var stats CacheStats
+	if sized, ok := cache.(interface{ Stats() CacheStats }); ok {
+		stats = sized.Stats()
+	}
+	stats.Hits = idx.cacheHits.Load()
+	stats.Misses = idx.cacheMisses.Load()
+	stats.Invalidations = idx.cacheInvalidations.Load()
+
+	return stats
+}
+
+// queryCache returns the configured cache, or nil.
+func (idx *Index) queryCache() QueryCache {
+	idx.cacheMu.RLock()
+	defer idx.cacheMu.RUnlock()
+
+	return idx.cache
+}
+
+// invalidateCache purges the cache after a write. The generation is bumped under the
+// same lock that guards stores, so searches that were in flight during the write cannot
+// store their results after the purge.
+func (idx *Index) invalidateCache() {
+	cache := idx.queryCache()
+	if cache == nil {
+		return
+	}
+
+	idx.cacheStoreMu.Lock()
+	defer idx.cacheStoreMu.Unlock()
+
+	idx.cacheGen.Add(1)
+	cache.Purge()
+	idx.cacheInvalidations.Add(1)
+}
+
+// storeCached saves results fetched during generation gen, unless a write has invalidated
+// the cache since.
+func (idx *Index) storeCached(cache QueryCache, key string, gen uint64, results []QueryResult) {
+	idx.cacheStoreMu.Lock()
+	defer idx.cacheStoreMu.Unlock()
+
+	if idx.cacheGen.Load() == gen {
+		cache.Set(key, cloneResults(results))
+	}
+}
+
+// cachedSearch serves requestData from the cache when possible and stores fresh results otherwise.
+func (idx *Index) cachedSearch(requestData QueryRequest, fetch func() ([]QueryResult, error)) ([]QueryResult, error) {
+	cache := idx.queryCache()
+	if cache == nil {
+		return fetch()
+	}
+
+	key, err := queryCacheKey(idx.Name, requestData)
+	if err != nil {
+		return fetch()
+	}
+
+	if results, ok := cache.Get(key); ok {
+		idx.cacheHits.Add(1)
+
+		return cloneResults(results), nil
+	}
+	idx.cacheMisses.Add(1)
+
+	gen := idx.cacheGen.Load()
+	results, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	idx.storeCached(cache, key, gen, results)
+
+	return results, nil
+}
+
+// queryCacheKey hashes the index name and the resolved request. The request holds the
+// normalized vector and a filter serialized with sorted keys, so equal queries hash equally.
+func queryCacheKey(indexName string, requestData QueryRequest) (string, error) {
+	payload, err := json.Marshal(requestData)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(indexName))
+	h.Write([]byte{0})
+	h.Write(payload)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// cloneResults deep-copies results so callers and the cache never share mutable state.
+func cloneResults(results []QueryResult) []QueryResult {
+	out := make([]QueryResult, len(results))
+	for i, r := range results {
+		r.Meta = cloneJSONMap(r.Meta)
+		r.Filter = cloneJSONMap(r.Filter)
+		r.Vector = slices.Clone(r.Vector)
+		r.rawMeta = slices.Clone(r.rawMeta)
+		out[i] = r
+	}
+
+	return out
+}
+
+// cloneJSONMap deep-copies a decoded JSON object.
+func cloneJSONMap(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return nil
+	}
+
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = cloneJSONValue(v)
+	}
+
+	return out
+}
+
+// cloneJSONValue deep-copies nested objects and arrays; other decoded JSON values are immutable.
+func cloneJSONValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return cloneJSONMap(t)
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, item := range t {
+			out[i] = cloneJSONValue(item)
+		}
+
+		return out
+	default:
+		return v
+	}
+}
+
+// LRUQueryCache is an in-process QueryCache bounded by approximate size in bytes,
+// evicting the least recently used entries first. Entries older than the TTL are misses.
+type LRUQueryCache struct {
+	maxBytes int64
+	ttl      time.Duration
+
+	mu        sync.Mutex
+	order     *list.List // Front is most recently used
+	entries   map[string]*list.Element
+	bytes     int64
+	evictions uint64
+}
+
+// lruEntry is a cached result set.
+type lruEntry struct {
+	key     string
+	results []QueryResult
+	size    int64
+	expires time.Time
+}
+
+// NewLRUQueryCache creates a cache holding up to maxBytes of results for up to ttl each.
+// A ttl of zero keeps entries until they are evicted or invalidated.
+func NewLRUQueryCache(maxBytes int64, ttl time.Duration) *LRUQueryCache {
+	return &LRUQueryCache{
+		maxBytes: maxBytes,
+		ttl:      ttl,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get returns the results stored under key if present and not expired.
+func (c *LRUQueryCache) Get(key string) ([]QueryResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if c.ttl > 0 && time.Now().After(entry.expires) {
+		c.remove(elem)
+		c.evictions++
+
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+
+	return entry.results, true
+}
+
+// Set stores results under key, evicting older entries to stay within the size bound.
+// Result sets larger than the whole bound are not cached.
+func (c *LRUQueryCache) Set(key string, results []QueryResult) {
+	size := estimateResultsSize(results)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	if size > c.maxBytes {
+		return
+	}
+
+	for c.bytes+size > c.maxBytes {
+		c.remove(c.order.Back())
+		c.evictions++
+	}
+
+	entry := &lruEntry{key: key, results: results, size: size}
+	if c.ttl > 0 {
+		entry.expires = time.Now().Add(c.ttl)
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	c.bytes += size
+}
+
+// Purge drops every entry.
+func (c *LRUQueryCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	clear(c.entries)
+	c.bytes = 0
+}
+
+// Stats reports evictions and current size. Hits and misses are counted by the Index.
+func (c *LRUQueryCache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return CacheStats{
+		Evictions: c.evictions,
+		Entries:   len(c.entries),
+		Bytes:     c.bytes,
+	}
+}
+
+// remove unlinks elem. The caller holds c.mu.
+func (c *LRUQueryCache) remove(elem *list.Element) {
+	entry := elem.Value.(*lruEntry)
+	c.order.Remove(elem)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.size
+}
+
+// estimateResultsSize approximates the memory held by results.
+func estimateResultsSize(results []QueryResult) int64 {
+	const resultOverhead = 128 // Struct, slice and map headers
+
+	var size int64
+	for _, r := range results {
+		size += resultOverhead + int64(len(r.ID)) + int64(4*len(r.Vector))
+		size += estimateValueSize(r.Meta) + estimateValueSize(r.Filter)
+	}
+
+	return size
+}
+
+// estimateValueSize approximates the memory held by a decoded JSON value.
+func estimateValueSize(v interface{}) int64 {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		size := int64(48)
+		for k, item := range val {
+			size += 16 + int64(len(k)) + estimateValueSize(item)
+		}
+
+		return size
+	case []interface{}:
+		size := int64(24)
+		for _, item := range val {
+			size += estimateValueSize(item)
+		}
+
+		return size
+	case string:
+		return 16 + int64(len(val))
+	default:
+		return 16
+	}
+}
@@ -0,0 +1,423 @@
+package endee
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// DefaultIngestBatchSize is the number of vectors an IngestRunner sends per request when no size is given.
+const DefaultIngestBatchSize = 100
+
+// CheckpointEntry records one batch acknowledged by the server.
+// Start and End are offsets into the ingest source, End being exclusive.
+type CheckpointEntry struct {
+	Index   string `json:"index"`
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+	FirstID string `json:"first_id"`
+	LastID  string `json:"last_id"`
+}
+
+// CheckpointJournal is an append-only on-disk log of acknowledged ingest batches.
+//
+// Every entry is written as a single checksummed line and fsynced before Append
+// returns. A line left half-written by a crash fails its checksum and is truncated
+// away on the next open, so the journal never claims a batch the server did not ack.
+type CheckpointJournal struct {
+	path    string
+	mu      sync.Mutex
+	file    *os.File
+	entries []CheckpointEntry
+}
+
+// OpenCheckpointJournal opens or creates the journal at path and loads its committed entries.
+func OpenCheckpointJournal(path string) (*CheckpointJournal, error) {
+	_, statErr := os.Stat(path)
+	created := errors.Is(statErr, os.ErrNotExist)
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint journal: %w", err)
+	}
+
+	entries, validSize, err := readJournalEntries(file)
+	if err != nil {
+		_ = file.Close()
+
+		return nil, err
+	}
+
+	// Drop any torn tail so new entries are not appended to garbage
+	if err := file.Truncate(validSize); err != nil {
+		_ = file.Close()
+
+		return nil, fmt.Errorf("failed to truncate checkpoint journal: %w", err)
+	}
+	if _, err := file.Seek(validSize, io.SeekStart); err != nil {
+		_ = file.Close()
+
+		return nil, fmt.Errorf("failed to seek checkpoint journal: %w", err)
+	}
+
+	if created {
+		// Persist the directory entry so the journal itself survives a crash
+		if dir, err := os.Open(filepath.Dir(path)); err == nil {
+			_ = dir.Sync()
+			_ = dir.Close()
+		}
+	}
+
+	return &CheckpointJournal{
+		path:    path,
+		file:    file,
+		entries: entries,
+	}, nil
+}
+
+// readJournalEntries parses journal lines until the first incomplete or corrupt one.
+// It returns the valid entries and the byte length of the valid prefix.
+func readJournalEntries(r io.Reader) ([]CheckpointEntry, int64, error) {
+	var entries []CheckpointEntry
+	var validSize int64
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// A line without its trailing newline was never fully written
+			return entries, validSize, nil
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read checkpoint journal: %w", err)
+		}
+
+		entry, ok := decodeJournalLine(line[:len(line)-1])
+		if !ok {
+			return entries, validSize, nil
+		}
+
+		entries = append(entries, entry)
+		validSize += int64(len(line))
+	}
+}
+
+// encodeJournalLine formats an entry as "<crc32 hex> <json>\n".
+func encodeJournalLine(entry CheckpointEntry) ([]byte, error) {
+	payload, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal checkpoint entry: %w", err)
+	}
+
+	line := make([]byte, 0, len(payload)+10)
+	line = strconv.AppendUint(line, uint64(crc32.ChecksumIEEE(payload)), 16)
+	line = append(line, ' ')
+	line = append(line, payload...)
+	line = append(line, '\n')
+
+	return line, nil
+}
+
+// decodeJournalLine parses a line produced by encodeJournalLine, verifying its checksum.
+func decodeJournalLine(line []byte) (CheckpointEntry, bool) {
+	sep := bytes.IndexByte(line, ' ')
+	if sep <= 0 {
+		return CheckpointEntry{}, false
+	}
+
+	sum, err := strconv.ParseUint(string(line[:sep]), 16, 32)
+	if err != nil {
+		return CheckpointEntry{}, false
+	}
+
+	payload := line[sep+1:]
+	if crc32.ChecksumIEEE(payload) != uint32(sum) {
+		return CheckpointEntry{}, false
+	}
+
+	var entry CheckpointEntry
+	if err := json.Unmarshal(payload, &entry); err != nil {
+		return CheckpointEntry{}, false
+	}
+
+	return entry, true
+}
+
+// Path returns the location of the journal file.
+func (j *CheckpointJournal) Path() string {
+	return j.path
+}
+
+// Entries returns a copy of the committed entries in the order they were written.
+func (j *CheckpointJournal) Entries() []CheckpointEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries := make([]CheckpointEntry, len(j.entries))
+	copy(entries, j.entries)
+
+	return entries
+}
+
+// Append durably records an acknowledged batch.
+func (j *CheckpointJournal) Append(entry CheckpointEntry) error {
+	if entry.Start < 0 || entry.End <= entry.Start {
+		return fmt.Errorf("invalid checkpoint range [%d, %d)", entry.Start, entry.End)
+	}
+
+	line, err := encodeJournalLine(entry)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return errors.New("checkpoint journal is closed")
+	}
+
+	if _, err := j.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write checkpoint entry: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync checkpoint journal: %w", err)
+	}
+
+	j.entries = append(j.entries, entry)
+
+	return nil
+}
+
+// CommittedOffset returns the end of the contiguous range of committed offsets starting at zero.
+func (j *CheckpointJournal) CommittedOffset() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	ranges := make([]CheckpointEntry, len(j.entries))
+	copy(ranges, j.entries)
+	sort.Slice(ranges, func(a, b int) bool { return ranges[a].Start < ranges[b].Start })
+
+	offset := 0
+	for _, r := range ranges {
+		if r.Start > offset {
+			break
+		}
+		if r.End > offset {
+			offset = r.End
+		}
+	}
+
+	return offset
+}
+
+// isCommitted reports whether every offset in [start, end) is covered by a journal entry.
+func (j *CheckpointJournal) isCommitted(start, end int) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for _, e := range j.entries {
+		if e.Start <= start && e.End >= end {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reset discards all entries so the next run starts from offset zero.
+func (j *CheckpointJournal) Reset() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return errors.New("checkpoint journal is closed")
+	}
+
+	if err := j.file.Truncate(0); err != nil {
+		return fmt.Errorf("failed to truncate checkpoint journal: %w", err)
+	}
+	if _, err := j.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek checkpoint journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync checkpoint journal: %w", err)
+	}
+
+	j.entries = nil
+
+	return nil
+}
+
+// Close closes the underlying journal file.
+func (j *CheckpointJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+
+	err := j.file.Close()
+	j.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to close checkpoint journal: %w", err)
+	}
+
+	return nil
+}
+
+// IngestReader supplies vectors to an IngestRunner.
+// ReadBatch must return the same items for the same offset on every run; a short or empty batch marks the end.
+type IngestReader interface {
+	ReadBatch(ctx context.Context, offset, limit int) ([]VectorItem, error)
+}
+
+// SliceIngestReader adapts an in-memory slice to IngestReader.
+type SliceIngestReader []VectorItem
+
+// ReadBatch returns up to limit items starting at offset.
+func (s SliceIngestReader) ReadBatch(_ context.Context, offset, limit int) ([]VectorItem, error) {
+	if offset >= len(s) {
+		return nil, nil
+	}
+
+	end := offset + limit
+	if end > len(s) {
+		end = len(s)
+	}
+
+	return s[offset:end], nil
+}
+
+// Len returns the number of items in the slice.
+func (s SliceIngestReader) Len() int {
+	return len(s)
+}
+
+// IngestResult summarizes a single IngestRunner run.
+type IngestResult struct {
+	ResumedFrom int // Offset the run started at, taken from the journal
+	Ingested    int // Vectors acknowledged during this run
+	Skipped     int // Vectors skipped because the journal already covered them
+	Batches     int // Requests sent during this run
+}
+
+// IngestRunner upserts a large source in batches and checkpoints every acknowledged batch,
+// so an interrupted backfill resumes where it stopped instead of starting over.
+type IngestRunner struct {
+	idx       *Index
+	journal   *CheckpointJournal
+	batchSize int
+}
+
+// NewIngestRunner creates a runner that writes to idx and checkpoints into journal.
+// A batchSize of zero uses DefaultIngestBatchSize.
+func (idx *Index) NewIngestRunner(journal *CheckpointJournal, batchSize int) (*IngestRunner, error) {
+	if journal == nil {
+		return nil, errors.New("checkpoint journal cannot be nil")
+	}
+
+	if batchSize == 0 {
+		batchSize = DefaultIngestBatchSize
+	}
+	if batchSize < 0 || batchSize > MaxVectorsPerBatch {
+		return nil, fmt.Errorf("batch size must be between 1 and %d", MaxVectorsPerBatch)
+	}
+
+	for _, entry := range journal.Entries() {
+		if entry.Index != idx.Name {
+			return nil, fmt.Errorf("checkpoint journal %s belongs to index %s, not %s", journal.Path(), entry.Index, idx.Name)
+		}
+	}
+
+	return &IngestRunner{
+		idx:       idx,
+		journal:   journal,
+		batchSize: batchSize,
+	}, nil
+}
+
+// Run ingests reader from the last committed offset until it is exhausted or ctx is cancelled.
+// When reader has a Len() int method, progress events report the remaining total.
+// Batches rejected with 429/5xx are retried with backoff, up to the MaxRetries of
+// WithAdaptiveBatching. WithDryRun and WithStreaming are rejected, since a dry run
+// must not commit ranges to the journal.
+func (r *IngestRunner) Run(ctx context.Context, reader IngestReader, opts ...UpsertOption) (IngestResult, error) {
+	cfg := newUpsertConfig(opts)
+	if err := cfg.rejectDryRunAndStreaming("an ingest run"); err != nil {
+		return IngestResult{}, err
+	}
+	batchCfg := cfg.adaptive.withDefaults()
+
+	offset := r.journal.CommittedOffset()
+	result := IngestResult{ResumedFrom: offset}
+
+	total := 0
+	if sized, ok := reader.(interface{ Len() int }); ok && sized.Len() > offset {
+		total = sized.Len() - offset
+	}
+
+	cfg.progress = newProgressTracker(cfg.progressFn, total)
+	defer cfg.progress.finish()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, fmt.Errorf("ingest cancelled at offset %d: %w", offset, err)
+		}
+
+		batch, err := reader.ReadBatch(ctx, offset, r.batchSize)
+		if err != nil {
+			return result, fmt.Errorf("failed to read batch at offset %d: %w", offset, err)
+		}
+		if len(batch) == 0 {
+			return result, nil
+		}
+
+		end := offset + len(batch)
+
+		if r.journal.isCommitted(offset, end) {
+			result.Skipped += len(batch)
+		} else {
+			if err := r.idx.validateUpsertBatch(batch); err != nil {
+				return result, fmt.Errorf("invalid batch at offset %d: %w", offset, err)
+			}
+			encoded, err := r.idx.encodeVectorItems(batch)
+			if err != nil {
+				return result, fmt.Errorf("invalid batch at offset %d: %w", offset, err)
+			}
+			if err := r.idx.sendAdaptiveBatch(ctx, newEncodedBatch(encoded), nil, batchCfg, cfg); err != nil {
+				return result, fmt.Errorf("upsert failed at offset %d: %w", offset, err)
+			}
+
+			entry := CheckpointEntry{
+				Index:   r.idx.Name,
+				Start:   offset,
+				End:     end,
+				FirstID: batch[0].ID,
+				LastID:  batch[len(batch)-1].ID,
+			}
+			if err := r.journal.Append(entry); err != nil {
+				return result, err
+			}
+
+			result.Ingested += len(batch)
+			result.Batches++
+		}
+
+		offset = end
+		if len(batch) < r.batchSize {
+			return result, nil
+		}
+	}
+}
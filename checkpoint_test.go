@@ -0,0 +1,330 @@
+package endee
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func openTestJournal(t *testing.T, path string) *CheckpointJournal {
+	t.Helper()
+
+	journal, err := OpenCheckpointJournal(path)
+	if err != nil {
+		t.Fatalf("OpenCheckpointJournal: %v", err)
+	}
+	t.Cleanup(func() { _ = journal.Close() })
+
+	return journal
+}
+
+func appendTestEntries(t *testing.T, journal *CheckpointJournal, ranges ...[2]int) {
+	t.Helper()
+
+	for _, r := range ranges {
+		entry := CheckpointEntry{Index: "docs", Start: r[0], End: r[1], FirstID: "a", LastID: "b"}
+		if err := journal.Append(entry); err != nil {
+			t.Fatalf("Append(%v): %v", r, err)
+		}
+	}
+}
+
+func TestCheckpointJournalTruncatesTornTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ingest.journal")
+
+	journal := openTestJournal(t, path)
+	appendTestEntries(t, journal, [2]int{0, 10}, [2]int{10, 20})
+	if err := journal.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validSize := info.Size()
+
+	// Simulate a crash halfway through writing the third line
+	line, err := encodeJournalLine(CheckpointEntry{Index: "docs", Start: 20, End: 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write(line[:len(line)/2]); err != nil {
+		t.Fatal(err)
+	}
+	_ = f.Close()
+
+	reopened := openTestJournal(t, path)
+	if got := len(reopened.Entries()); got != 2 {
+		t.Fatalf("entries after torn tail = %d, want 2", got)
+	}
+	if got := reopened.CommittedOffset(); got != 20 {
+		t.Fatalf("CommittedOffset = %d, want 20", got)
+	}
+
+	info, err = os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != validSize {
+		t.Fatalf("file size after open = %d, want torn tail truncated to %d", info.Size(), validSize)
+	}
+
+	// New entries must land after the valid prefix, not after the garbage
+	appendTestEntries(t, reopened, [2]int{20, 30})
+	_ = reopened.Close()
+
+	final := openTestJournal(t, path)
+	if got := final.CommittedOffset(); got != 30 {
+		t.Fatalf("CommittedOffset after append = %d, want 30", got)
+	}
+}
+
+func TestCheckpointJournalStopsAtChecksumMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ingest.journal")
+
+	journal := openTestJournal(t, path)
+	appendTestEntries(t, journal, [2]int{0, 10}, [2]int{10, 20}, [2]int{20, 30})
+	_ = journal.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := encodeJournalLine(CheckpointEntry{Index: "docs", Start: 0, End: 10, FirstID: "a", LastID: "b"})
+
+	// Flip a payload byte of the second line while keeping it well-formed
+	corrupt := append([]byte{}, data...)
+	pos := len(first) + len(first) - 3
+	corrupt[pos] ^= 0x01
+	if err := os.WriteFile(path, corrupt, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened := openTestJournal(t, path)
+	entries := reopened.Entries()
+	if len(entries) != 1 || entries[0].End != 10 {
+		t.Fatalf("entries after corruption = %+v, want only [0, 10)", entries)
+	}
+	if got := reopened.CommittedOffset(); got != 10 {
+		t.Fatalf("CommittedOffset = %d, want 10", got)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != int64(len(first)) {
+		t.Fatalf("file size = %d, want replay to stop after the first line (%d bytes)", info.Size(), len(first))
+	}
+}
+
+// ingestStub records upserted IDs and fails the request numbered failAt (1-based) once,
+// with failStatus or 400 by default.
+type ingestStub struct {
+	mu         sync.Mutex
+	requests   int
+	failAt     int
+	failStatus int
+	ids        []string
+}
+
+func (s *ingestStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	if s.requests == s.failAt {
+		status := s.failStatus
+		if status == 0 {
+			status = http.StatusBadRequest
+		}
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"error":"rejected"}`))
+
+		return
+	}
+
+	var tuples []vectorTuple
+	if err := msgpack.Unmarshal(body, &tuples); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+	for _, tuple := range tuples {
+		s.ids = append(s.ids, tuple.ID)
+	}
+}
+
+func TestIngestRunnerResumeSkipsCommittedRanges(t *testing.T) {
+	stub := &ingestStub{failAt: 2}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	idx := &Index{Name: "docs", URL: srv.URL, Dimension: 2, SpaceType: L2}
+
+	source := make(SliceIngestReader, 25)
+	for i := range source {
+		source[i] = VectorItem{ID: "v" + strconv.Itoa(i), Vector: []float32{float32(i), 1}}
+	}
+
+	path := filepath.Join(t.TempDir(), "ingest.journal")
+	journal := openTestJournal(t, path)
+
+	runner, err := idx.NewIngestRunner(journal, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The second batch fails, so only [0, 10) is committed
+	result, err := runner.Run(context.Background(), source)
+	if err == nil {
+		t.Fatal("first run succeeded, want failure on the second batch")
+	}
+	if result.Ingested != 10 {
+		t.Fatalf("first run ingested %d, want 10", result.Ingested)
+	}
+	_ = journal.Close()
+
+	// Resume from a fresh journal handle, as a restarted process would
+	resumed := openTestJournal(t, path)
+	runner, err = idx.NewIngestRunner(resumed, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err = runner.Run(context.Background(), source)
+	if err != nil {
+		t.Fatalf("resumed run: %v", err)
+	}
+	if result.ResumedFrom != 10 || result.Ingested != 15 || result.Batches != 2 {
+		t.Fatalf("resumed run = %+v, want ResumedFrom 10, Ingested 15, Batches 2", result)
+	}
+
+	stub.mu.Lock()
+	defer stub.mu.Unlock()
+
+	seen := make(map[string]int)
+	for _, id := range stub.ids {
+		seen[id]++
+	}
+	for i := range source {
+		id := source[i].ID
+		if seen[id] != 1 {
+			t.Errorf("id %s upserted %d times, want exactly once", id, seen[id])
+		}
+	}
+	if got := resumed.CommittedOffset(); got != 25 {
+		t.Fatalf("CommittedOffset = %d, want 25", got)
+	}
+}
+
+func TestIngestRunnerSkipsRangesCommittedOutOfOrder(t *testing.T) {
+	stub := &ingestStub{}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	idx := &Index{Name: "docs", URL: srv.URL, Dimension: 2, SpaceType: L2}
+
+	source := make(SliceIngestReader, 30)
+	for i := range source {
+		source[i] = VectorItem{ID: "v" + strconv.Itoa(i), Vector: []float32{float32(i), 1}}
+	}
+
+	journal := openTestJournal(t, filepath.Join(t.TempDir(), "ingest.journal"))
+	// [20, 30) was acknowledged by an earlier run, but [0, 20) was not
+	appendTestEntries(t, journal, [2]int{20, 30})
+
+	runner, err := idx.NewIngestRunner(journal, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := runner.Run(context.Background(), source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ResumedFrom != 0 || result.Ingested != 20 || result.Skipped != 10 {
+		t.Fatalf("run = %+v, want ResumedFrom 0, Ingested 20, Skipped 10", result)
+	}
+	if len(stub.ids) != 20 {
+		t.Fatalf("server received %d vectors, want 20", len(stub.ids))
+	}
+}
+
+func TestIngestRunnerRetriesTransientErrors(t *testing.T) {
+	stub := &ingestStub{failAt: 2, failStatus: http.StatusServiceUnavailable}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	idx := &Index{Name: "docs", URL: srv.URL, Dimension: 2, SpaceType: L2}
+
+	source := make(SliceIngestReader, 20)
+	for i := range source {
+		source[i] = VectorItem{ID: "v" + strconv.Itoa(i), Vector: []float32{float32(i), 1}}
+	}
+
+	journal := openTestJournal(t, filepath.Join(t.TempDir(), "ingest.journal"))
+	runner, err := idx.NewIngestRunner(journal, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := runner.Run(context.Background(), source)
+	if err != nil {
+		t.Fatalf("run with one 503: %v", err)
+	}
+	if result.Ingested != 20 || journal.CommittedOffset() != 20 {
+		t.Fatalf("run = %+v, committed %d, want all 20 ingested", result, journal.CommittedOffset())
+	}
+	if len(stub.ids) != 20 {
+		t.Fatalf("server stored %d vectors, want 20", len(stub.ids))
+	}
+}
+
+func TestIngestRunnerRejectsDryRunAndStreaming(t *testing.T) {
+	stub := &ingestStub{}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	idx := &Index{Name: "docs", URL: srv.URL, Dimension: 2, SpaceType: L2}
+	source := SliceIngestReader{{ID: "a", Vector: []float32{1, 1}}}
+
+	for name, opt := range map[string]UpsertOption{
+		"dry run":   WithDryRun(nil),
+		"streaming": WithStreaming(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			journal := openTestJournal(t, filepath.Join(t.TempDir(), "ingest.journal"))
+			runner, err := idx.NewIngestRunner(journal, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := runner.Run(context.Background(), source, opt); err == nil {
+				t.Fatal("Run succeeded, want the option rejected")
+			}
+			if got := journal.CommittedOffset(); got != 0 {
+				t.Fatalf("CommittedOffset = %d, want 0", got)
+			}
+		})
+	}
+
+	if stub.requests != 0 {
+		t.Fatalf("server received %d requests, want 0", stub.requests)
+	}
+}
@@ -0,0 +1,133 @@
+package endee
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// SetCoalescing turns request coalescing on or off for this handle.
+//
+// When enabled, identical queries (same resolved request) and GetVector calls for the same
+// ID that overlap in time share one request to the server. Every caller receives its own
+// copy of the results, and each caller's context is honored on its own: a caller that gives
+// up returns immediately, and the shared request is cancelled only when every caller has.
+func (idx *Index) SetCoalescing(enabled bool) {
+	idx.coalesce.Store(enabled)
+}
+
+// flightGroup runs one function per key at a time and shares its result with every caller.
+// The zero value is ready to use.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// flightCall is a shared in-flight request.
+type flightCall struct {
+	done    chan struct{}
+	val     interface{}
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// do runs fn once for all concurrent callers with the same key. fn receives a context that
+// carries the first caller's values but is cancelled only when every waiting caller has gone.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(context.Context) (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+
+	call, ok := g.calls[key]
+	if ok {
+		call.waiters++
+	} else {
+		flightCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &flightCall{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		g.calls[key] = call
+
+		go func() {
+			defer cancel()
+			call.val, call.err = fn(flightCtx)
+
+			g.mu.Lock()
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+			g.mu.Unlock()
+
+			close(call.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.val, call.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			// Nobody is left to receive the result; stop the request and let the next caller start fresh
+			call.cancel()
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+
+		return nil, fmt.Errorf("request cancelled: %w", ctx.Err())
+	}
+}
+
+// coalescedSearch shares fetchSearch between identical concurrent queries when coalescing is on.
+// Each caller receives its own deep copy of the shared results.
+func (idx *Index) coalescedSearch(ctx context.Context, requestData QueryRequest) ([]QueryResult, error) {
+	if !idx.coalesce.Load() {
+		return idx.fetchSearch(ctx, requestData)
+	}
+
+	key, err := queryCacheKey(idx.Name, requestData)
+	if err != nil {
+		return idx.fetchSearch(ctx, requestData)
+	}
+
+	val, err := idx.flights.do(ctx, "search:"+key, func(ctx context.Context) (interface{}, error) {
+		return idx.fetchSearch(ctx, requestData)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cloneResults(val.([]QueryResult)), nil
+}
+
+// coalescedGetVector shares fetchVector between concurrent lookups of the same ID when coalescing is on.
+func (idx *Index) coalescedGetVector(ctx context.Context, id string) (VectorItem, error) {
+	if !idx.coalesce.Load() {
+		return idx.fetchVector(ctx, id)
+	}
+
+	val, err := idx.flights.do(ctx, "get:"+id, func(ctx context.Context) (interface{}, error) {
+		return idx.fetchVector(ctx, id)
+	})
+	if err != nil {
+		return VectorItem{}, err
+	}
+
+	return cloneVectorItem(val.(VectorItem)), nil
+}
+
+// cloneVectorItem deep-copies item so callers never share mutable state.
+func cloneVectorItem(item VectorItem) VectorItem {
+	item.Vector = slices.Clone(item.Vector)
+	item.SparseIndices = slices.Clone(item.SparseIndices)
+	item.SparseValues = slices.Clone(item.SparseValues)
+	item.Meta = cloneJSONMap(item.Meta)
+	item.Filter = cloneJSONMap(item.Filter)
+
+	return item
+}
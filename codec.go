@@ -0,0 +1,308 @@
+package endee
+
+import (
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// Compile-time checks that the wire tuples bypass msgpack reflection.
+var (
+	_ msgpack.CustomEncoder = (*vectorTuple)(nil)
+	_ msgpack.CustomDecoder = (*vectorTuple)(nil)
+	_ msgpack.CustomDecoder = (*resultTuple)(nil)
+)
+
+// vectorTuple is the wire form of a stored vector:
+// [id, meta, filter, norm, vector, (sparse_indices, sparse_values)].
+// It is sent by upserts and returned by the vector/get endpoint.
+type vectorTuple struct {
+	ID            string
+	Meta          []byte // zlib-compressed JSON
+	Filter        string // JSON object
+	Norm          float32
+	Vector        []float32
+	SparseIndices []int
+	SparseValues  []float32
+
+	// scale divides every Vector element on encode, so callers can pass the raw
+	// input and its norm instead of a normalized copy. Zero means no scaling.
+	scale float32
+}
+
+// EncodeMsgpack writes the tuple without reflection.
+func (t *vectorTuple) EncodeMsgpack(enc *msgpack.Encoder) error {
+	hasSparse := len(t.SparseIndices) > 0 && len(t.SparseValues) > 0
+	fields := 5
+	if hasSparse {
+		fields = 7
+	}
+
+	if err := enc.EncodeArrayLen(fields); err != nil {
+		return fmt.Errorf("failed to encode vector: %w", err)
+	}
+	if err := enc.EncodeString(t.ID); err != nil {
+		return fmt.Errorf("failed to encode vector: %w", err)
+	}
+	if err := enc.EncodeBytes(t.Meta); err != nil {
+		return fmt.Errorf("failed to encode vector: %w", err)
+	}
+	if err := enc.EncodeString(t.Filter); err != nil {
+		return fmt.Errorf("failed to encode vector: %w", err)
+	}
+	if err := enc.EncodeFloat32(t.Norm); err != nil {
+		return fmt.Errorf("failed to encode vector: %w", err)
+	}
+	if err := encodeFloat32Array(enc, t.Vector, t.scale); err != nil {
+		return fmt.Errorf("failed to encode vector: %w", err)
+	}
+
+	if hasSparse {
+		if err := enc.EncodeArrayLen(len(t.SparseIndices)); err != nil {
+			return fmt.Errorf("failed to encode sparse indices: %w", err)
+		}
+		for _, v := range t.SparseIndices {
+			if err := enc.EncodeInt(int64(v)); err != nil {
+				return fmt.Errorf("failed to encode sparse indices: %w", err)
+			}
+		}
+		if err := encodeFloat32Array(enc, t.SparseValues, 0); err != nil {
+			return fmt.Errorf("failed to encode sparse values: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// DecodeMsgpack reads the tuple, converting numeric arrays straight into typed slices.
+func (t *vectorTuple) DecodeMsgpack(dec *msgpack.Decoder) error {
+	n, err := dec.DecodeArrayLen()
+	if err != nil {
+		return fmt.Errorf("failed to decode vector: %w", err)
+	}
+	if n < 5 {
+		return fmt.Errorf("invalid response format: expected 5 elements, got %d", n)
+	}
+
+	if t.ID, err = decodeLooseString(dec); err != nil {
+		return fmt.Errorf("failed to decode vector id: %w", err)
+	}
+	if t.Meta, err = decodeLooseBytes(dec); err != nil {
+		return fmt.Errorf("failed to decode vector meta: %w", err)
+	}
+	if t.Filter, err = decodeLooseString(dec); err != nil {
+		return fmt.Errorf("failed to decode vector filter: %w", err)
+	}
+	if t.Norm, err = decodeLooseFloat32(dec); err != nil {
+		return fmt.Errorf("failed to decode vector norm: %w", err)
+	}
+	if t.Vector, err = decodeFloat32Array(dec); err != nil {
+		return fmt.Errorf("failed to decode vector data: %w", err)
+	}
+
+	consumed := 5
+	if n >= 7 {
+		if t.SparseIndices, err = decodeIntArray(dec); err != nil {
+			return fmt.Errorf("failed to decode sparse indices: %w", err)
+		}
+		if t.SparseValues, err = decodeFloat32Array(dec); err != nil {
+			return fmt.Errorf("failed to decode sparse values: %w", err)
+		}
+		consumed = 7
+	}
+
+	return skipElements(dec, n-consumed)
+}
+
+// resultTuple is the wire form of a search hit: [similarity, id, meta, filter, norm, (vector)].
+type resultTuple struct {
+	Similarity float32
+	ID         string
+	Meta       []byte // zlib-compressed JSON
+	Filter     string // JSON object
+	Norm       float32
+	Vector     []float32
+
+	// fields is the number of elements the server sent; fewer than 5 marks a malformed hit.
+	fields int
+}
+
+// DecodeMsgpack reads the tuple, converting the vector straight into []float32.
+func (t *resultTuple) DecodeMsgpack(dec *msgpack.Decoder) error {
+	n, err := dec.DecodeArrayLen()
+	if err != nil {
+		return fmt.Errorf("failed to decode result: %w", err)
+	}
+	t.fields = n
+	if n < 5 {
+		// Leave the hit for the caller to skip or reject
+		return skipElements(dec, n)
+	}
+
+	if t.Similarity, err = decodeLooseFloat32(dec); err != nil {
+		return fmt.Errorf("failed to decode result similarity: %w", err)
+	}
+	if t.ID, err = decodeLooseString(dec); err != nil {
+		return fmt.Errorf("failed to decode result id: %w", err)
+	}
+	if t.Meta, err = decodeLooseBytes(dec); err != nil {
+		return fmt.Errorf("failed to decode result meta: %w", err)
+	}
+	if t.Filter, err = decodeLooseString(dec); err != nil {
+		return fmt.Errorf("failed to decode result filter: %w", err)
+	}
+	if t.Norm, err = decodeLooseFloat32(dec); err != nil {
+		return fmt.Errorf("failed to decode result norm: %w", err)
+	}
+
+	consumed := 5
+	if n > 5 {
+		if t.Vector, err = decodeFloat32Array(dec); err != nil {
+			return fmt.Errorf("failed to decode result vector: %w", err)
+		}
+		consumed = 6
+	}
+
+	return skipElements(dec, n-consumed)
+}
+
+// encodeFloat32Array writes values as a msgpack array of float32, dividing each by scale when it is non-zero.
+func encodeFloat32Array(enc *msgpack.Encoder, values []float32, scale float32) error {
+	if err := enc.EncodeArrayLen(len(values)); err != nil {
+		return fmt.Errorf("failed to encode array length: %w", err)
+	}
+	for _, v := range values {
+		if scale != 0 {
+			v /= scale
+		}
+		if err := enc.EncodeFloat32(v); err != nil {
+			return fmt.Errorf("failed to encode float: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// decodeFloat32Array reads an array of any msgpack numbers into []float32. A nil array yields nil.
+func decodeFloat32Array(dec *msgpack.Decoder) ([]float32, error) {
+	n, err := dec.DecodeArrayLen()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode array length: %w", err)
+	}
+	if n < 0 {
+		return nil, nil
+	}
+
+	values := make([]float32, n)
+	for i := range values {
+		if values[i], err = decodeLooseFloat32(dec); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// decodeIntArray reads an array of msgpack integers into []int. A nil array yields nil.
+func decodeIntArray(dec *msgpack.Decoder) ([]int, error) {
+	n, err := dec.DecodeArrayLen()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode array length: %w", err)
+	}
+	if n < 0 {
+		return nil, nil
+	}
+
+	values := make([]int, n)
+	for i := range values {
+		v, err := dec.DecodeInt64()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode integer: %w", err)
+		}
+		values[i] = int(v)
+	}
+
+	return values, nil
+}
+
+// decodeLooseFloat32 reads any msgpack number as float32; nil reads as zero.
+func decodeLooseFloat32(dec *msgpack.Decoder) (float32, error) {
+	code, err := dec.PeekCode()
+	if err != nil {
+		return 0, fmt.Errorf("failed to peek value: %w", err)
+	}
+	if code == msgpcode.Nil {
+		return 0, decodeNil(dec)
+	}
+
+	v, err := dec.DecodeFloat64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode float: %w", err)
+	}
+
+	return float32(v), nil
+}
+
+// decodeLooseString reads a str or bin value as a string; nil reads as "" and other types are formatted.
+func decodeLooseString(dec *msgpack.Decoder) (string, error) {
+	code, err := dec.PeekCode()
+	if err != nil {
+		return "", fmt.Errorf("failed to peek value: %w", err)
+	}
+
+	if code == msgpcode.Nil || msgpcode.IsString(code) || msgpcode.IsBin(code) {
+		s, err := dec.DecodeString()
+		if err != nil {
+			return "", fmt.Errorf("failed to decode string: %w", err)
+		}
+
+		return s, nil
+	}
+
+	v, err := dec.DecodeInterface()
+	if err != nil {
+		return "", fmt.Errorf("failed to decode value: %w", err)
+	}
+
+	return safeStringConvert(v), nil
+}
+
+// decodeLooseBytes reads a str or bin value as bytes; nil and other types read as nil.
+func decodeLooseBytes(dec *msgpack.Decoder) ([]byte, error) {
+	code, err := dec.PeekCode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to peek value: %w", err)
+	}
+
+	if msgpcode.IsString(code) || msgpcode.IsBin(code) {
+		b, err := dec.DecodeBytes()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode bytes: %w", err)
+		}
+
+		return b, nil
+	}
+
+	return nil, skipElements(dec, 1)
+}
+
+// decodeNil consumes a nil value.
+func decodeNil(dec *msgpack.Decoder) error {
+	if err := dec.DecodeNil(); err != nil {
+		return fmt.Errorf("failed to decode nil: %w", err)
+	}
+
+	return nil
+}
+
+// skipElements discards the next n values.
+func skipElements(dec *msgpack.Decoder, n int) error {
+	for i := 0; i < n; i++ {
+		if err := dec.Skip(); err != nil {
+			return fmt.Errorf("failed to skip value: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,338 @@
+package endee
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestVectorTupleRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		tuple vectorTuple
+	}{
+		{
+			name:  "dense",
+			tuple: vectorTuple{ID: "a", Meta: []byte{0x78, 0x9c, 0x01}, Filter: `{"k":"v"}`, Norm: 2, Vector: []float32{0.5, -1, 3}},
+		},
+		{
+			name: "sparse",
+			tuple: vectorTuple{
+				ID: "b", Filter: "{}", Norm: 1, Vector: []float32{1, 0},
+				SparseIndices: []int{3, 70000}, SparseValues: []float32{0.25, 4},
+			},
+		},
+		{
+			name:  "empty",
+			tuple: vectorTuple{ID: "c", Vector: []float32{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := msgpack.Marshal(&tt.tuple)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+
+			var got vectorTuple
+			if err := msgpack.Unmarshal(data, &got); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.tuple) {
+				t.Fatalf("round trip = %+v, want %+v", got, tt.tuple)
+			}
+		})
+	}
+}
+
+func TestVectorTupleScalesOnEncode(t *testing.T) {
+	data, err := msgpack.Marshal(&vectorTuple{ID: "a", Vector: []float32{2, 4}, Norm: 2, scale: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got vectorTuple
+	if err := msgpack.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Vector, []float32{1, 2}) {
+		t.Fatalf("Vector = %v, want [1 2]", got.Vector)
+	}
+}
+
+func TestVectorTupleDecodeLoose(t *testing.T) {
+	tests := []struct {
+		name string
+		wire []interface{}
+		want vectorTuple
+	}{
+		{
+			name: "nil fields and arrays",
+			wire: []interface{}{"a", nil, nil, nil, nil},
+			want: vectorTuple{ID: "a"},
+		},
+		{
+			name: "integer and float64 numbers",
+			wire: []interface{}{"a", "meta", "{}", int64(3), []interface{}{1, 2.5, uint8(4)}},
+			want: vectorTuple{ID: "a", Meta: []byte("meta"), Filter: "{}", Norm: 3, Vector: []float32{1, 2.5, 4}},
+		},
+		{
+			name: "six elements ignore a lone sparse field",
+			wire: []interface{}{"a", nil, "{}", 1.0, []float32{1}, []int{5}},
+			want: vectorTuple{ID: "a", Filter: "{}", Norm: 1, Vector: []float32{1}},
+		},
+		{
+			name: "nil sparse arrays",
+			wire: []interface{}{"a", nil, "{}", 1.0, []float32{1}, nil, nil},
+			want: vectorTuple{ID: "a", Filter: "{}", Norm: 1, Vector: []float32{1}},
+		},
+		{
+			name: "trailing elements are skipped",
+			wire: []interface{}{"a", nil, "{}", 1.0, []float32{1}, []int{5}, []float32{0.5}, "extra"},
+			want: vectorTuple{ID: "a", Filter: "{}", Norm: 1, Vector: []float32{1}, SparseIndices: []int{5}, SparseValues: []float32{0.5}},
+		},
+		{
+			name: "numeric id",
+			wire: []interface{}{42, nil, nil, 0, []float32{}},
+			want: vectorTuple{ID: "42", Vector: []float32{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := msgpack.Marshal(tt.wire)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got vectorTuple
+			if err := msgpack.Unmarshal(data, &got); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("decoded %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVectorTupleRejectsShortTuple(t *testing.T) {
+	data, err := msgpack.Marshal([]interface{}{"a", nil, "{}", 1.0})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got vectorTuple
+	if err := msgpack.Unmarshal(data, &got); err == nil {
+		t.Fatal("decoding a 4-element tuple succeeded, want error")
+	}
+}
+
+func TestResultTupleDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		wire []interface{}
+		want resultTuple
+	}{
+		{
+			name: "five elements",
+			wire: []interface{}{0.75, "a", []byte("meta"), `{"k":1}`, 2.0},
+			want: resultTuple{Similarity: 0.75, ID: "a", Meta: []byte("meta"), Filter: `{"k":1}`, Norm: 2, fields: 5},
+		},
+		{
+			name: "six elements with vector",
+			wire: []interface{}{0.5, "a", nil, "{}", 1.0, []interface{}{1, 0.5}},
+			want: resultTuple{Similarity: 0.5, ID: "a", Filter: "{}", Norm: 1, Vector: []float32{1, 0.5}, fields: 6},
+		},
+		{
+			name: "seven elements skip the extra field",
+			wire: []interface{}{0.5, "a", nil, "{}", 1.0, []float32{1}, map[string]interface{}{"x": 1}},
+			want: resultTuple{Similarity: 0.5, ID: "a", Filter: "{}", Norm: 1, Vector: []float32{1}, fields: 7},
+		},
+		{
+			name: "nil fields and vector",
+			wire: []interface{}{nil, "a", nil, nil, nil, nil},
+			want: resultTuple{ID: "a", fields: 6},
+		},
+		{
+			name: "short tuple is marked, not decoded",
+			wire: []interface{}{0.5, "a", nil},
+			want: resultTuple{fields: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := msgpack.Marshal([]interface{}{tt.wire, []interface{}{0.1, "next", nil, "", 1.0}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []resultTuple
+			if err := msgpack.Unmarshal(data, &got); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if len(got) != 2 {
+				t.Fatalf("decoded %d results, want 2", len(got))
+			}
+			if !reflect.DeepEqual(got[0], tt.want) {
+				t.Fatalf("decoded %+v, want %+v", got[0], tt.want)
+			}
+			// The following hit must still decode, so the stream stayed aligned
+			if got[1].ID != "next" || got[1].fields != 5 {
+				t.Fatalf("next result = %+v, want id next", got[1])
+			}
+		})
+	}
+}
+
+// benchmarkResults builds a search response of n hits with vectors of dim elements.
+func benchmarkResults(b *testing.B, n, dim int) []byte {
+	b.Helper()
+
+	results := make([]interface{}, n)
+	for i := range results {
+		vector := make([]float32, dim)
+		for j := range vector {
+			vector[j] = float32(j) / float32(dim)
+		}
+		results[i] = []interface{}{float32(i) / float32(n), fmt.Sprintf("id-%d", i), []byte("compressed-meta"), `{"tag":"x"}`, float32(1), vector}
+	}
+
+	data, err := msgpack.Marshal(results)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return data
+}
+
+// benchmarkVector builds a vector/get response with dim dense and 64 sparse elements.
+func benchmarkVector(b *testing.B, dim int) []byte {
+	b.Helper()
+
+	tuple := vectorTuple{ID: "id", Meta: []byte("compressed-meta"), Filter: `{"tag":"x"}`, Norm: 1, Vector: make([]float32, dim)}
+	for j := range tuple.Vector {
+		tuple.Vector[j] = float32(j) / float32(dim)
+	}
+	for j := 0; j < 64; j++ {
+		tuple.SparseIndices = append(tuple.SparseIndices, j*31)
+		tuple.SparseValues = append(tuple.SparseValues, float32(j))
+	}
+
+	data, err := msgpack.Marshal(&tuple)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return data
+}
+
+// interfaceFloat32 mirrors the type switch the []interface{} decode path used.
+func interfaceFloat32(val interface{}) float32 {
+	switch v := val.(type) {
+	case float32:
+		return v
+	case float64:
+		return float32(v)
+	case int64:
+		return float32(v)
+	case uint64:
+		return float32(v)
+	case int8:
+		return float32(v)
+	case uint8:
+		return float32(v)
+	default:
+		return 0
+	}
+}
+
+// interfaceFloat32Slice converts a decoded []interface{} of numbers.
+func interfaceFloat32Slice(val interface{}) []float32 {
+	items, _ := val.([]interface{})
+	values := make([]float32, len(items))
+	for i, v := range items {
+		values[i] = interfaceFloat32(v)
+	}
+
+	return values
+}
+
+func BenchmarkResultDecodeInterface(b *testing.B) {
+	data := benchmarkResults(b, 100, 768)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		var raw [][]interface{}
+		if err := msgpack.Unmarshal(data, &raw); err != nil {
+			b.Fatal(err)
+		}
+		for _, r := range raw {
+			_ = interfaceFloat32(r[0])
+			_ = safeStringConvert(r[1])
+			_ = safeStringConvert(r[3])
+			_ = interfaceFloat32(r[4])
+			_ = interfaceFloat32Slice(r[5])
+		}
+	}
+}
+
+func BenchmarkResultDecodeTuple(b *testing.B) {
+	data := benchmarkResults(b, 100, 768)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		var results []resultTuple
+		if err := msgpack.Unmarshal(data, &results); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVectorDecodeInterface(b *testing.B) {
+	data := benchmarkVector(b, 1536)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		var raw []interface{}
+		if err := msgpack.Unmarshal(data, &raw); err != nil {
+			b.Fatal(err)
+		}
+		_ = safeStringConvert(raw[0])
+		_ = safeStringConvert(raw[2])
+		_ = interfaceFloat32(raw[3])
+		_ = interfaceFloat32Slice(raw[4])
+		if indices, ok := raw[5].([]interface{}); ok {
+			sparse := make([]int, len(indices))
+			for j, v := range indices {
+				switch n := v.(type) {
+				case int64:
+					sparse[j] = int(n)
+				case uint64:
+					sparse[j] = int(n)
+				}
+			}
+		}
+		_ = interfaceFloat32Slice(raw[6])
+	}
+}
+
+func BenchmarkVectorDecodeTuple(b *testing.B) {
+	data := benchmarkVector(b, 1536)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	for b.Loop() {
+		var tuple vectorTuple
+		if err := msgpack.Unmarshal(data, &tuple); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package endee
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultEmbedBatchSize is the number of texts OpenAIEmbedder sends per request when no size is given.
+const DefaultEmbedBatchSize = 96
+
+// Embedder turns text into dense vectors.
+// Embed returns one vector per input text, in input order; implementations split large
+// inputs into batches as their backend requires. Dimension returns the vector length,
+// or 0 when it is not known in advance.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	Dimension() int
+}
+
+// TextItem is a document to embed and upsert. Hybrid indexes also need its sparse
+// vector, which is stored alongside the embedding as given.
+type TextItem struct {
+	ID            string
+	Text          string
+	Meta          map[string]interface{}
+	Filter        map[string]interface{}
+	SparseIndices []int
+	SparseValues  []float32
+}
+
+// SetEmbedder sets the Embedder used by QueryText and UpsertTexts on this handle.
+func (idx *Index) SetEmbedder(embedder Embedder) {
+	idx.embedderMu.Lock()
+	defer idx.embedderMu.Unlock()
+
+	idx.embedder = embedder
+}
+
+// QueryText embeds text and runs the query described by opts with it as the dense vector.
+// The text is also passed to the reranker, if one is configured.
+func (idx *Index) QueryText(ctx context.Context, text string, opts QueryOptions) ([]QueryResult, error) {
+	vectors, err := idx.embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Vector = vectors[0]
+	if opts.QueryText == "" {
+		opts.QueryText = text
+	}
+
+	return idx.QueryWithOptions(ctx, opts)
+}
+
+// UpsertTexts embeds every item's text in one Embed call and upserts the resulting vectors.
+// IDs, filters, sparse vectors and the batch size are checked before anything is embedded.
+func (idx *Index) UpsertTexts(ctx context.Context, items []TextItem, opts ...UpsertOption) error {
+	if len(items) == 0 {
+		return nil
+	}
+	if err := idx.validateTextItems(items); err != nil {
+		return err
+	}
+
+	texts := make([]string, len(items))
+	for i, item := range items {
+		texts[i] = item.Text
+	}
+
+	vectors, err := idx.embed(ctx, texts)
+	if err != nil {
+		return err
+	}
+
+	vectorItems := make([]VectorItem, len(items))
+	for i, item := range items {
+		vectorItems[i] = VectorItem{
+			ID:            item.ID,
+			Vector:        vectors[i],
+			Meta:          item.Meta,
+			Filter:        item.Filter,
+			SparseIndices: item.SparseIndices,
+			SparseValues:  item.SparseValues,
+		}
+	}
+
+	return idx.UpsertWithContext(ctx, vectorItems, opts...)
+}
+
+// validateTextItems applies the upsert checks that do not depend on the embeddings,
+// so an invalid batch fails without paying for an Embed call.
+func (idx *Index) validateTextItems(items []TextItem) error {
+	if len(items) > MaxVectorsPerBatch {
+		return fmt.Errorf("cannot insert more than %d vectors at a time", MaxVectorsPerBatch)
+	}
+
+	// A placeholder of the right dimension lets the shared checks cover IDs and filters
+	placeholder := make([]float32, idx.Dimension)
+	vectorItems := make([]VectorItem, len(items))
+	for i, item := range items {
+		vectorItems[i] = VectorItem{
+			ID:            item.ID,
+			Vector:        placeholder,
+			Filter:        item.Filter,
+			SparseIndices: item.SparseIndices,
+			SparseValues:  item.SparseValues,
+		}
+	}
+
+	return idx.validateUpsertBatch(vectorItems)
+}
+
+// embed runs the configured embedder and checks its output against the index dimension.
+func (idx *Index) embed(ctx context.Context, texts []string) ([][]float32, error) {
+	idx.embedderMu.RLock()
+	embedder := idx.embedder
+	idx.embedderMu.RUnlock()
+
+	if embedder == nil {
+		return nil, errors.New("no embedder configured; call SetEmbedder first")
+	}
+	for i, text := range texts {
+		if strings.TrimSpace(text) == "" {
+			return nil, fmt.Errorf("text at position %d is empty", i)
+		}
+	}
+
+	if dim := embedder.Dimension(); dim != 0 && dim != idx.Dimension {
+		return nil, fmt.Errorf("embedder dimension %d does not match index dimension %d", dim, idx.Dimension)
+	}
+
+	vectors, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed text: %w", err)
+	}
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(texts))
+	}
+	for i, v := range vectors {
+		if len(v) != idx.Dimension {
+			return nil, fmt.Errorf("embedding %d has dimension %d, index expects %d", i, len(v), idx.Dimension)
+		}
+	}
+
+	return vectors, nil
+}
+
+// OpenAIEmbedder calls an OpenAI-compatible /embeddings endpoint.
+type OpenAIEmbedder struct {
+	BaseURL    string       // API root such as "https://api.openai.com/v1"; "/embeddings" is appended
+	Token      string       // Sent as a bearer token when set
+	Model      string       // Embedding model name
+	Dimensions int          // Requested vector length; 0 uses the model's default and reports it as unknown
+	BatchSize  int          // Texts per request (default DefaultEmbedBatchSize)
+	HTTP       *http.Client // Client to use (default http.DefaultClient)
+}
+
+// openAIEmbedRequest is the body sent by OpenAIEmbedder.
+type openAIEmbedRequest struct {
+	Model          string   `json:"model"`
+	Input          []string `json:"input"`
+	Dimensions     int      `json:"dimensions,omitempty"`
+	EncodingFormat string   `json:"encoding_format"`
+}
+
+// openAIEmbedResponse is the body OpenAIEmbedder expects back.
+type openAIEmbedResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Dimension implements Embedder.
+func (e *OpenAIEmbedder) Dimension() int {
+	return e.Dimensions
+}
+
+// Embed implements Embedder, sending texts in batches of BatchSize.
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	batchSize := e.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultEmbedBatchSize
+	}
+
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += batchSize {
+		end := min(start+batchSize, len(texts))
+
+		batch, err := e.embedBatch(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batch...)
+	}
+
+	return vectors, nil
+}
+
+// embedBatch sends one embeddings request and returns the vectors in input order.
+func (e *OpenAIEmbedder) embedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	body, err := json.Marshal(openAIEmbedRequest{
+		Model:          e.Model,
+		Input:          texts,
+		Dimensions:     e.Dimensions,
+		EncodingFormat: "float",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal embeddings request: %w", err)
+	}
+
+	url := strings.TrimSuffix(e.BaseURL, "/") + "/embeddings"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embeddings request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if e.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+e.Token)
+	}
+
+	client := e.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute embeddings request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+		return nil, fmt.Errorf("embeddings endpoint returned status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	var parsed openAIEmbedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode embeddings response: %w", err)
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, d := range parsed.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("embeddings response has out-of-range index %d", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	for i, v := range vectors {
+		if v == nil {
+			return nil, fmt.Errorf("embeddings response is missing input %d", i)
+		}
+	}
+
+	return vectors, nil
+}
@@ -0,0 +1,225 @@
+package endee
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// embeddingsStub answers /embeddings with [len(text), position] for each input, listed in
+// reverse order. Inputs equal to skip are left out of the response.
+type embeddingsStub struct {
+	skip string
+
+	mu       sync.Mutex
+	requests [][]string
+}
+
+func (s *embeddingsStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/embeddings" || r.Header.Get("Authorization") != "Bearer secret" {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	var req openAIEmbedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req.Input)
+	s.mu.Unlock()
+
+	type datum struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	}
+	var data []datum
+	for i := len(req.Input) - 1; i >= 0; i-- {
+		if req.Input[i] == s.skip {
+			continue
+		}
+		data = append(data, datum{Index: i, Embedding: []float32{float32(len(req.Input[i])), float32(i)}})
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+func newTestEmbedder(t *testing.T, stub *embeddingsStub) *OpenAIEmbedder {
+	t.Helper()
+
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+
+	return &OpenAIEmbedder{BaseURL: srv.URL + "/v1/", Token: "secret", Model: "test", BatchSize: 2}
+}
+
+func TestOpenAIEmbedderBatchesAndReorders(t *testing.T) {
+	stub := &embeddingsStub{}
+	embedder := newTestEmbedder(t, stub)
+
+	texts := []string{"a", "bb", "ccc", "dddd", "eeeee"}
+	vectors, err := embedder.Embed(context.Background(), texts)
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+
+	want := [][]float32{{1, 0}, {2, 1}, {3, 0}, {4, 1}, {5, 0}}
+	if !reflect.DeepEqual(vectors, want) {
+		t.Fatalf("vectors = %v, want %v", vectors, want)
+	}
+
+	wantRequests := [][]string{{"a", "bb"}, {"ccc", "dddd"}, {"eeeee"}}
+	if !reflect.DeepEqual(stub.requests, wantRequests) {
+		t.Fatalf("requests = %v, want %v", stub.requests, wantRequests)
+	}
+}
+
+func TestOpenAIEmbedderRejectsMissingIndex(t *testing.T) {
+	embedder := newTestEmbedder(t, &embeddingsStub{skip: "ccc"})
+
+	_, err := embedder.Embed(context.Background(), []string{"a", "bb", "ccc", "dddd"})
+	if err == nil || !strings.Contains(err.Error(), "missing input 0") {
+		t.Fatalf("Embed error = %v, want missing input 0 of the second batch", err)
+	}
+}
+
+// countingEmbedder records how many times Embed was called.
+type countingEmbedder struct {
+	dim   int
+	calls int
+}
+
+func (e *countingEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+
+	vectors := make([][]float32, len(texts))
+	for i := range vectors {
+		vectors[i] = make([]float32, e.dim)
+		vectors[i][0] = 1
+	}
+
+	return vectors, nil
+}
+
+func (e *countingEmbedder) Dimension() int {
+	return e.dim
+}
+
+func TestUpsertTextsValidatesBeforeEmbedding(t *testing.T) {
+	tests := []struct {
+		name   string
+		hybrid bool
+		items  []TextItem
+	}{
+		{
+			name:  "duplicate ids",
+			items: []TextItem{{ID: "a", Text: "x"}, {ID: "a", Text: "y"}},
+		},
+		{
+			name:  "empty id",
+			items: []TextItem{{ID: " ", Text: "x"}},
+		},
+		{
+			name:  "filter value too long",
+			items: []TextItem{{ID: "a", Text: "x", Filter: map[string]interface{}{"k": strings.Repeat("v", MaxValueBytes+1)}}},
+		},
+		{
+			name:  "too many items",
+			items: make([]TextItem, MaxVectorsPerBatch+1),
+		},
+		{
+			name:   "hybrid index without sparse vector",
+			hybrid: true,
+			items:  []TextItem{{ID: "a", Text: "x"}},
+		},
+		{
+			name:   "mismatched sparse vector",
+			hybrid: true,
+			items:  []TextItem{{ID: "a", Text: "x", SparseIndices: []int{1, 2}, SparseValues: []float32{1}}},
+		},
+		{
+			name:  "sparse vector on dense index",
+			items: []TextItem{{ID: "a", Text: "x", SparseIndices: []int{1}, SparseValues: []float32{1}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			embedder := &countingEmbedder{dim: 2}
+			idx := &Index{Name: "docs", URL: "http://127.0.0.1:0", Dimension: 2, SpaceType: L2, IsHybrid: tt.hybrid}
+			idx.SetEmbedder(embedder)
+
+			if err := idx.UpsertTexts(context.Background(), tt.items); err == nil {
+				t.Fatal("UpsertTexts succeeded, want a validation error")
+			}
+			if embedder.calls != 0 {
+				t.Fatalf("Embed called %d times, want 0", embedder.calls)
+			}
+		})
+	}
+}
+
+func TestUpsertTextsEmptyIsNoop(t *testing.T) {
+	embedder := &countingEmbedder{dim: 2}
+	idx := &Index{Name: "docs", URL: "http://127.0.0.1:0", Dimension: 2, SpaceType: L2}
+	idx.SetEmbedder(embedder)
+
+	if err := idx.UpsertTexts(context.Background(), nil); err != nil {
+		t.Fatalf("UpsertTexts(nil) = %v, want nil", err)
+	}
+	if embedder.calls != 0 {
+		t.Fatalf("Embed called %d times, want 0", embedder.calls)
+	}
+}
+
+func TestUpsertTextsHybrid(t *testing.T) {
+	var stored []vectorTuple
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := msgpack.Unmarshal(body, &stored); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	embedder := &countingEmbedder{dim: 2}
+	idx := &Index{Name: "docs", URL: srv.URL, Dimension: 2, SpaceType: L2, IsHybrid: true}
+	idx.SetEmbedder(embedder)
+
+	items := []TextItem{
+		{ID: "a", Text: "alpha", SparseIndices: []int{3, 7}, SparseValues: []float32{0.5, 1.5}},
+		{ID: "b", Text: "beta", SparseIndices: []int{1}, SparseValues: []float32{2}},
+	}
+	if err := idx.UpsertTexts(context.Background(), items); err != nil {
+		t.Fatalf("UpsertTexts on a hybrid index: %v", err)
+	}
+	if embedder.calls != 1 {
+		t.Fatalf("Embed called %d times, want 1", embedder.calls)
+	}
+
+	if len(stored) != len(items) {
+		t.Fatalf("server stored %d vectors, want %d", len(stored), len(items))
+	}
+	for i, tuple := range stored {
+		if tuple.ID != items[i].ID ||
+			!reflect.DeepEqual(tuple.SparseIndices, items[i].SparseIndices) ||
+			!reflect.DeepEqual(tuple.SparseValues, items[i].SparseValues) {
+			t.Errorf("stored %+v, want id %s with its sparse vector", tuple, items[i].ID)
+		}
+		if !reflect.DeepEqual(tuple.Vector, []float32{1, 0}) {
+			t.Errorf("stored vector %v, want the embedding [1 0]", tuple.Vector)
+		}
+	}
+}
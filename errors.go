@@ -2,6 +2,7 @@ package endee
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -119,3 +120,22 @@ func checkError(resp *http.Response) error {
 		return &APIError{StatusCode: resp.StatusCode, Message: msg}
 	}
 }
+
+// isRetryableError reports whether err is a response status listed in HTTPStatusCodes.
+func isRetryableError(err error) bool {
+	var serverErr *ServerError
+	if errors.As(err, &serverErr) {
+		return true
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		for _, code := range HTTPStatusCodes {
+			if apiErr.StatusCode == code {
+				return true
+			}
+		}
+	}
+
+	return false
+}
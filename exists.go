@@ -0,0 +1,101 @@
+package endee
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Exists reports whether a vector with id is stored in the index. A missing vector
+// yields false with a nil error. The server answers 404 for a missing index too, so a
+// not-found response is confirmed against the index info; if the index itself is gone,
+// the NotFoundError is returned. Any other failure is returned as an error.
+//
+// The server has no lighter lookup than vector/get, so the vector is still transferred,
+// but the response is discarded without decompressing metadata or decoding the vector.
+func (idx *Index) Exists(ctx context.Context, id string) (bool, error) {
+	found, err := idx.lookup(ctx, id)
+	if err != nil || found {
+		return found, err
+	}
+	if err := idx.confirmIndexExists(ctx); err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
+
+// lookup fetches id and reports whether the server found it, treating any 404 as
+// not found without telling a missing vector from a missing index.
+func (idx *Index) lookup(ctx context.Context, id string) (bool, error) {
+	if id == "" {
+		return false, errors.New("id cannot be empty")
+	}
+
+	jsonData, err := fastJSONMarshal(map[string]string{"id": id})
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal request data: %w", err)
+	}
+
+	resp, err := idx.executeRequestWithContext(ctx, "POST", "index/%s/vector/get", jsonData, "application/json")
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if err := checkError(resp); err != nil {
+		var notFound *NotFoundError
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	// Drain so the connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return true, nil
+}
+
+// ExistsMany checks many IDs concurrently. Repeated and empty IDs are dropped.
+// The map holds an answer for every ID that could be checked; failures for the rest are
+// joined into the returned error, each naming its ID. When any ID comes back not found,
+// the index is confirmed once; if it does not exist, no answers are returned and the
+// error wraps its NotFoundError.
+func (idx *Index) ExistsMany(ctx context.Context, ids []string) (map[string]bool, error) {
+	ids = dedupeIDs(ids)
+	exists := make([]bool, len(ids))
+	errs := make([]error, len(ids))
+
+	next := runBounded(ctx, len(ids), DefaultGetConcurrency, func(i int) {
+		exists[i], errs[i] = idx.lookup(ctx, ids[i])
+	})
+	for i := next; i < len(ids); i++ {
+		errs[i] = fmt.Errorf("exists check cancelled: %w", ctx.Err())
+	}
+
+	for i := range ids {
+		if errs[i] == nil && !exists[i] {
+			if err := idx.confirmIndexExists(ctx); err != nil {
+				return map[string]bool{}, err
+			}
+
+			break
+		}
+	}
+
+	found := make(map[string]bool, len(ids))
+	var failures []error
+	for i, id := range ids {
+		if errs[i] != nil {
+			failures = append(failures, fmt.Errorf("id %s: %w", id, errs[i]))
+
+			continue
+		}
+		found[id] = exists[i]
+	}
+
+	return found, errors.Join(failures...)
+}
@@ -0,0 +1,55 @@
+package endee
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestExistsMissingVector(t *testing.T) {
+	srv := newVectorStoreServer(t, "docs", "a")
+	idx := &Index{Name: "docs", URL: srv.URL, Dimension: 2, SpaceType: L2}
+
+	for id, want := range map[string]bool{"a": true, "b": false} {
+		got, err := idx.Exists(context.Background(), id)
+		if err != nil || got != want {
+			t.Errorf("Exists(%q) = %v, %v, want %v, nil", id, got, err, want)
+		}
+	}
+}
+
+func TestExistsMissingIndexIsAnError(t *testing.T) {
+	srv := newVectorStoreServer(t, "docs", "a")
+	idx := &Index{Name: "dosc", URL: srv.URL, Dimension: 2, SpaceType: L2}
+
+	var notFound *NotFoundError
+
+	ok, err := idx.Exists(context.Background(), "a")
+	if ok || !errors.As(err, &notFound) {
+		t.Fatalf("Exists = %v, %v, want a NotFoundError", ok, err)
+	}
+
+	found, err := idx.ExistsMany(context.Background(), []string{"a", "b"})
+	if len(found) != 0 || !errors.As(err, &notFound) {
+		t.Fatalf("ExistsMany = %v, %v, want no answers and a NotFoundError", found, err)
+	}
+}
+
+func TestExistsMany(t *testing.T) {
+	srv := newVectorStoreServer(t, "docs", "a", "c")
+	idx := &Index{Name: "docs", URL: srv.URL, Dimension: 2, SpaceType: L2}
+
+	found, err := idx.ExistsMany(context.Background(), []string{"a", "b", "c", "", "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]bool{"a": true, "b": false, "c": true}
+	if len(found) != len(want) {
+		t.Fatalf("found = %v, want %v", found, want)
+	}
+	for id, w := range want {
+		if found[id] != w {
+			t.Errorf("found[%q] = %v, want %v", id, found[id], w)
+		}
+	}
+}
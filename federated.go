@@ -0,0 +1,178 @@
+package endee
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// MergeStrategy turns one index's ranked results into scores comparable across indexes.
+// Implementations receive results best first and return one score per result, higher is better.
+type MergeStrategy interface {
+	Scores(results []QueryResult) []float64
+}
+
+// RawMerge ranks by raw similarity. It is only meaningful when every index shares a
+// space type, and FederatedQuery rejects it otherwise.
+type RawMerge struct{}
+
+// Scores returns each result's similarity.
+func (RawMerge) Scores(results []QueryResult) []float64 {
+	return similarities(results)
+}
+
+// MinMaxMerge rescales each index's similarities onto [0, 1].
+type MinMaxMerge struct{}
+
+// Scores returns min-max normalized similarities.
+func (MinMaxMerge) Scores(results []QueryResult) []float64 {
+	return minMaxNormalize(similarities(results))
+}
+
+// ZScoreMerge rescales each index's similarities to standard deviations from their mean.
+type ZScoreMerge struct{}
+
+// Scores returns z-score normalized similarities.
+func (ZScoreMerge) Scores(results []QueryResult) []float64 {
+	return zScoreNormalize(similarities(results))
+}
+
+// RRFMerge ranks by reciprocal rank fusion, ignoring similarity values entirely.
+type RRFMerge struct {
+	K int // Rank constant (default DefaultRRFRankConstant)
+}
+
+// Scores returns 1/(K+rank) for each result.
+func (m RRFMerge) Scores(results []QueryResult) []float64 {
+	k := m.K
+	if k <= 0 {
+		k = DefaultRRFRankConstant
+	}
+
+	scores := make([]float64, len(results))
+	for i := range results {
+		scores[i] = rrfScore(i, k)
+	}
+
+	return scores
+}
+
+// FederatedOptions controls FederatedQuery.
+type FederatedOptions struct {
+	Strategy     MergeStrategy // How per-index results are made comparable (default RRFMerge{})
+	TopK         int           // Results to return after merging (default: the query's TopK)
+	AllowPartial bool          // Return results from healthy indexes when some fail
+}
+
+// FederatedResult is a merged result tagged with the index it came from.
+type FederatedResult struct {
+	QueryResult
+	Index string  // Name of the source index
+	Score float64 // Merged score the result was ranked by
+}
+
+// FederatedQuery sends the same query to every target concurrently and merges the
+// results into one ranked list. A vector found in several indexes under the same ID
+// is returned once, from the index where it scored highest.
+//
+// By default any failing index fails the whole query. With AllowPartial, results from
+// the remaining indexes are returned together with an error describing the failures.
+func (nd *Endee) FederatedQuery(ctx context.Context, targets []*Index, opts QueryOptions, fed FederatedOptions) ([]FederatedResult, error) {
+	if len(targets) == 0 {
+		return nil, errors.New("at least one index is required")
+	}
+	for i, target := range targets {
+		if target == nil {
+			return nil, fmt.Errorf("index at position %d is nil", i)
+		}
+	}
+
+	strategy := fed.Strategy
+	if strategy == nil {
+		strategy = RRFMerge{}
+	}
+	if _, raw := strategy.(RawMerge); raw {
+		for _, target := range targets[1:] {
+			if target.SpaceType != targets[0].SpaceType {
+				return nil, fmt.Errorf("raw merge requires a shared space type: index %s uses %s, index %s uses %s",
+					targets[0].Name, targets[0].SpaceType, target.Name, target.SpaceType)
+			}
+		}
+	}
+
+	topK := fed.TopK
+	if topK <= 0 {
+		topK = DefaultTopK
+		if opts.TopK != nil {
+			topK = *opts.TopK
+		}
+	}
+
+	lists := make([][]QueryResult, len(targets))
+	errs := make([]error, len(targets))
+
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lists[i], errs[i] = target.QueryWithOptions(ctx, opts)
+		}()
+	}
+	wg.Wait()
+
+	var failures []error
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, fmt.Errorf("index %s: %w", targets[i].Name, err))
+		}
+	}
+	if len(failures) == len(targets) || (len(failures) > 0 && !fed.AllowPartial) {
+		return nil, fmt.Errorf("federated query failed: %w", errors.Join(failures...))
+	}
+
+	merged := mergeFederated(targets, lists, strategy, topK)
+	if len(failures) > 0 {
+		return merged, fmt.Errorf("federated query partially failed: %w", errors.Join(failures...))
+	}
+
+	return merged, nil
+}
+
+// mergeFederated scores each list, keeps the best occurrence of every ID and ranks the rest.
+func mergeFederated(targets []*Index, lists [][]QueryResult, strategy MergeStrategy, topK int) []FederatedResult {
+	best := make(map[string]int)
+	var merged []FederatedResult
+
+	for i, results := range lists {
+		if len(results) == 0 {
+			continue
+		}
+
+		scores := strategy.Scores(results)
+		for j, r := range results {
+			candidate := FederatedResult{QueryResult: r, Index: targets[i].Name, Score: scores[j]}
+			if pos, seen := best[r.ID]; seen {
+				if candidate.Score > merged[pos].Score {
+					merged[pos] = candidate
+				}
+
+				continue
+			}
+
+			best[r.ID] = len(merged)
+			merged = append(merged, candidate)
+		}
+	}
+
+	// Stable so ties keep target order, then per-index rank
+	sort.SliceStable(merged, func(a, b int) bool { return merged[a].Score > merged[b].Score })
+
+	if len(merged) > topK {
+		merged = merged[:topK]
+	}
+
+	return merged
+}
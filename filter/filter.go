@@ -0,0 +1,320 @@
+// Package filter builds and validates Endee filter expressions.
+//
+// A Filter produces the map passed as the filter argument of Index.Query,
+// Index.QueryWithContext and Index.DeleteVectorByFilter, shaped as
+// {"field": {"$op": value}}. Operator names, value types, key and value sizes
+// and $range bounds are checked before any request is made.
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+
+	endee "github.com/endee-io/endee-go-client"
+)
+
+// Supported operators.
+const (
+	OpEq    = "$eq"    // Exact match on a string or number
+	OpIn    = "$in"    // Match any of a list of strings
+	OpRange = "$range" // Inclusive numeric range
+)
+
+// Range bounds accepted by the server for $range.
+const (
+	RangeMin = 0
+	RangeMax = 999
+)
+
+// condition is a single operator applied to a field.
+type condition struct {
+	field string
+	op    string
+	value interface{}
+}
+
+// Filter is an immutable conjunction of conditions. The zero value matches everything.
+type Filter struct {
+	conds []condition
+	errs  []error
+}
+
+// Eq matches vectors whose field equals value. value must be a string or a number.
+func Eq(field string, value interface{}) Filter {
+	f := Filter{conds: []condition{{field: field, op: OpEq, value: value}}}
+	if err := validateEqValue(field, value); err != nil {
+		f.errs = append(f.errs, err)
+	}
+
+	return f
+}
+
+// In matches vectors whose field equals any of values.
+func In(field string, values ...string) Filter {
+	list := make([]string, len(values))
+	copy(list, values)
+
+	f := Filter{conds: []condition{{field: field, op: OpIn, value: list}}}
+	if err := validateInValues(field, list); err != nil {
+		f.errs = append(f.errs, err)
+	}
+
+	return f
+}
+
+// Range matches vectors whose field lies in [start, end]. Both bounds must be within [RangeMin, RangeMax].
+func Range(field string, start, end int) Filter {
+	f := Filter{conds: []condition{{field: field, op: OpRange, value: []int{start, end}}}}
+	if err := validateRangeBounds(field, float64(start), float64(end)); err != nil {
+		f.errs = append(f.errs, err)
+	}
+
+	return f
+}
+
+// And combines filters; a vector must match every condition.
+func And(filters ...Filter) Filter {
+	var out Filter
+	for _, f := range filters {
+		out.conds = append(out.conds, f.conds...)
+		out.errs = append(out.errs, f.errs...)
+	}
+
+	return out
+}
+
+// Err returns the validation errors collected while building the filter, or nil.
+func (f Filter) Err() error {
+	errs := append([]error{}, f.errs...)
+	for _, c := range f.conds {
+		if err := validateKey(c.field); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	seen := make(map[string]struct{}, len(f.conds))
+	for _, c := range f.conds {
+		key := c.field + "\x00" + c.op
+		if _, dup := seen[key]; dup {
+			errs = append(errs, fmt.Errorf("filter field %q has more than one %s condition", c.field, c.op))
+		}
+		seen[key] = struct{}{}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Build validates the filter and returns it in the map shape the client sends.
+// An empty filter builds to nil, which the query methods treat as "no filter".
+func (f Filter) Build() (map[string]interface{}, error) {
+	if err := f.Err(); err != nil {
+		return nil, err
+	}
+	if len(f.conds) == 0 {
+		return nil, nil
+	}
+
+	out := make(map[string]interface{}, len(f.conds))
+	for _, c := range f.conds {
+		ops, ok := out[c.field].(map[string]interface{})
+		if !ok {
+			ops = make(map[string]interface{}, 1)
+			out[c.field] = ops
+		}
+		ops[c.op] = c.value
+	}
+
+	return out, nil
+}
+
+// MustBuild is like Build but panics on an invalid filter.
+func (f Filter) MustBuild() map[string]interface{} {
+	m, err := f.Build()
+	if err != nil {
+		panic(err)
+	}
+
+	return m
+}
+
+// Validate checks a hand-written filter map, reporting unknown operators (such as "$In"),
+// wrong value types, oversized keys or values and out-of-range $range bounds.
+func Validate(m map[string]interface{}) error {
+	var errs []error
+
+	for field, raw := range m {
+		if err := validateKey(field); err != nil {
+			errs = append(errs, err)
+		}
+
+		ops, ok := raw.(map[string]interface{})
+		if !ok {
+			errs = append(errs, fmt.Errorf("filter field %q must map operators to values, got %T", field, raw))
+
+			continue
+		}
+		if len(ops) == 0 {
+			errs = append(errs, fmt.Errorf("filter field %q has no operator", field))
+		}
+
+		for op, value := range ops {
+			var err error
+			switch op {
+			case OpEq:
+				err = validateEqValue(field, value)
+			case OpIn:
+				var values []string
+				if values, err = toStringSlice(field, value); err == nil {
+					err = validateInValues(field, values)
+				}
+			case OpRange:
+				var bounds []float64
+				if bounds, err = toRangeBounds(field, value); err == nil {
+					err = validateRangeBounds(field, bounds[0], bounds[1])
+				}
+			default:
+				err = unknownOperatorError(field, op)
+			}
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// unknownOperatorError reports an unsupported operator, pointing out case mistakes.
+func unknownOperatorError(field, op string) error {
+	for _, known := range []string{OpEq, OpIn, OpRange} {
+		if strings.EqualFold(op, known) {
+			return fmt.Errorf("filter field %q: unknown operator %q (operators are case-sensitive, did you mean %q?)", field, op, known)
+		}
+	}
+
+	return fmt.Errorf("filter field %q: unknown operator %q (supported: %s, %s, %s)", field, op, OpEq, OpIn, OpRange)
+}
+
+// validateKey checks a field name against MaxKeyBytes.
+func validateKey(field string) error {
+	if field == "" {
+		return errors.New("filter field name must not be empty")
+	}
+	if len(field) > endee.MaxKeyBytes {
+		return fmt.Errorf("filter key %q exceeds %d bytes", field, endee.MaxKeyBytes)
+	}
+
+	return nil
+}
+
+// validateStringValue checks a string value against MaxValueBytes.
+func validateStringValue(field, value string) error {
+	if len(value) > endee.MaxValueBytes {
+		return fmt.Errorf("filter value for key %q exceeds %d bytes", field, endee.MaxValueBytes)
+	}
+
+	return nil
+}
+
+// validateEqValue accepts strings and finite numbers.
+func validateEqValue(field string, value interface{}) error {
+	if s, ok := value.(string); ok {
+		return validateStringValue(field, s)
+	}
+
+	n, ok := toNumber(value)
+	if !ok {
+		return fmt.Errorf("filter field %q: %s value must be a string or number, got %T", field, OpEq, value)
+	}
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return fmt.Errorf("filter field %q: %s value must be finite", field, OpEq)
+	}
+
+	return nil
+}
+
+// validateInValues requires a non-empty list of valid strings.
+func validateInValues(field string, values []string) error {
+	if len(values) == 0 {
+		return fmt.Errorf("filter field %q: %s requires at least one value", field, OpIn)
+	}
+	for _, v := range values {
+		if err := validateStringValue(field, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateRangeBounds requires RangeMin <= start <= end <= RangeMax.
+func validateRangeBounds(field string, start, end float64) error {
+	if start < RangeMin || end > RangeMax {
+		return fmt.Errorf("filter field %q: %s bounds must be within [%d, %d], got [%v, %v]", field, OpRange, RangeMin, RangeMax, start, end)
+	}
+	if start > end {
+		return fmt.Errorf("filter field %q: %s start %v is greater than end %v", field, OpRange, start, end)
+	}
+
+	return nil
+}
+
+// toNumber converts any Go numeric kind to float64.
+func toNumber(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// toStringSlice accepts []string or a slice whose elements are all strings.
+func toStringSlice(field string, value interface{}) ([]string, error) {
+	if values, ok := value.([]string); ok {
+		return values, nil
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("filter field %q: %s value must be a list of strings, got %T", field, OpIn, value)
+	}
+
+	values := make([]string, v.Len())
+	for i := range values {
+		s, ok := v.Index(i).Interface().(string)
+		if !ok {
+			return nil, fmt.Errorf("filter field %q: %s values must be strings, got %T", field, OpIn, v.Index(i).Interface())
+		}
+		values[i] = s
+	}
+
+	return values, nil
+}
+
+// toRangeBounds accepts a two-element list of numbers.
+func toRangeBounds(field string, value interface{}) ([]float64, error) {
+	v := reflect.ValueOf(value)
+	if (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) || v.Len() != 2 {
+		return nil, fmt.Errorf("filter field %q: %s value must be a [start, end] pair, got %v", field, OpRange, value)
+	}
+
+	bounds := make([]float64, 2)
+	for i := range bounds {
+		n, ok := toNumber(v.Index(i).Interface())
+		if !ok {
+			return nil, fmt.Errorf("filter field %q: %s bounds must be numbers, got %T", field, OpRange, v.Index(i).Interface())
+		}
+		bounds[i] = n
+	}
+
+	return bounds, nil
+}
@@ -0,0 +1,179 @@
+package filter
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	endee "github.com/endee-io/endee-go-client"
+)
+
+func TestBuildJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		want   string
+	}{
+		{name: "zero value", filter: Filter{}, want: `null`},
+		{name: "eq string", filter: Eq("lang", "en"), want: `{"lang":{"$eq":"en"}}`},
+		{name: "eq int", filter: Eq("year", 2024), want: `{"year":{"$eq":2024}}`},
+		{name: "eq float", filter: Eq("score", 0.5), want: `{"score":{"$eq":0.5}}`},
+		{name: "in", filter: In("tag", "a", "b"), want: `{"tag":{"$in":["a","b"]}}`},
+		{name: "range", filter: Range("age", 18, 65), want: `{"age":{"$range":[18,65]}}`},
+		{name: "range full span", filter: Range("age", RangeMin, RangeMax), want: `{"age":{"$range":[0,999]}}`},
+		{
+			name:   "and of fields",
+			filter: And(Eq("lang", "en"), In("tag", "x"), Range("age", 1, 2)),
+			want:   `{"age":{"$range":[1,2]},"lang":{"$eq":"en"},"tag":{"$in":["x"]}}`,
+		},
+		{
+			name:   "and on one field",
+			filter: And(Eq("n", 5), Range("n", 0, 10)),
+			want:   `{"n":{"$eq":5,"$range":[0,10]}}`,
+		},
+		{name: "nested and", filter: And(And(Eq("a", "1")), And()), want: `{"a":{"$eq":"1"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := tt.filter.Build()
+			if err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+			got, err := json.Marshal(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("Build() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInCopiesValues(t *testing.T) {
+	values := []string{"a", "b"}
+	f := In("tag", values...)
+	values[0] = "changed"
+
+	got, _ := json.Marshal(f.MustBuild())
+	if string(got) != `{"tag":{"$in":["a","b"]}}` {
+		t.Fatalf("Build() = %s, want the values at construction time", got)
+	}
+}
+
+func TestBuildErrors(t *testing.T) {
+	long := strings.Repeat("k", endee.MaxKeyBytes+1)
+	longValue := strings.Repeat("v", endee.MaxValueBytes+1)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   string
+	}{
+		{name: "eq bool", filter: Eq("f", true), want: "must be a string or number"},
+		{name: "eq NaN", filter: Eq("f", math.NaN()), want: "must be finite"},
+		{name: "eq Inf", filter: Eq("f", math.Inf(1)), want: "must be finite"},
+		{name: "eq long value", filter: Eq("f", longValue), want: "exceeds"},
+		{name: "in empty", filter: In("f"), want: "at least one value"},
+		{name: "in long value", filter: In("f", "ok", longValue), want: "exceeds"},
+		{name: "range below min", filter: Range("f", -1, 5), want: "bounds must be within"},
+		{name: "range above max", filter: Range("f", 0, 1000), want: "bounds must be within"},
+		{name: "range reversed", filter: Range("f", 9, 3), want: "greater than end"},
+		{name: "empty field", filter: Eq("", "x"), want: "must not be empty"},
+		{name: "long field", filter: Eq(long, "x"), want: "exceeds"},
+		{name: "duplicate condition", filter: And(Eq("f", "a"), Eq("f", "b")), want: "more than one $eq"},
+		{name: "error carried through and", filter: And(Eq("ok", "x"), In("f")), want: "at least one value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := tt.filter.Build()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Build() error = %v, want it to contain %q", err, tt.want)
+			}
+			if m != nil {
+				t.Fatalf("Build() returned %v alongside an error", m)
+			}
+		})
+	}
+}
+
+func TestMustBuildPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MustBuild did not panic on an invalid filter")
+		}
+	}()
+
+	In("f").MustBuild()
+}
+
+func TestValidate(t *testing.T) {
+	long := strings.Repeat("k", endee.MaxKeyBytes+1)
+	longValue := strings.Repeat("v", endee.MaxValueBytes+1)
+
+	tests := []struct {
+		name   string
+		filter map[string]interface{}
+		want   string // Empty means valid
+	}{
+		{name: "nil", filter: nil},
+		{name: "built filter", filter: And(Eq("a", "x"), In("b", "y"), Range("c", 1, 2)).MustBuild()},
+		{name: "decoded JSON", filter: map[string]interface{}{
+			"a": map[string]interface{}{"$in": []interface{}{"x", "y"}},
+			"b": map[string]interface{}{"$range": []interface{}{1.0, 2.0}},
+			"c": map[string]interface{}{"$eq": 3.0},
+		}},
+		{name: "empty field", filter: map[string]interface{}{"": map[string]interface{}{"$eq": "x"}}, want: "must not be empty"},
+		{name: "long field", filter: map[string]interface{}{long: map[string]interface{}{"$eq": "x"}}, want: "exceeds"},
+		{name: "not an operator map", filter: map[string]interface{}{"a": "x"}, want: "must map operators to values"},
+		{name: "no operator", filter: map[string]interface{}{"a": map[string]interface{}{}}, want: "has no operator"},
+		{name: "case mistake", filter: map[string]interface{}{"a": map[string]interface{}{"$In": []string{"x"}}}, want: `did you mean "$in"`},
+		{name: "unknown operator", filter: map[string]interface{}{"a": map[string]interface{}{"$gt": 1}}, want: "supported: $eq, $in, $range"},
+		{name: "eq wrong type", filter: map[string]interface{}{"a": map[string]interface{}{"$eq": []int{1}}}, want: "must be a string or number"},
+		{name: "eq NaN", filter: map[string]interface{}{"a": map[string]interface{}{"$eq": math.NaN()}}, want: "must be finite"},
+		{name: "eq long value", filter: map[string]interface{}{"a": map[string]interface{}{"$eq": longValue}}, want: "exceeds"},
+		{name: "in not a list", filter: map[string]interface{}{"a": map[string]interface{}{"$in": "x"}}, want: "must be a list of strings"},
+		{name: "in non-string element", filter: map[string]interface{}{"a": map[string]interface{}{"$in": []interface{}{"x", 1}}}, want: "values must be strings"},
+		{name: "in empty", filter: map[string]interface{}{"a": map[string]interface{}{"$in": []string{}}}, want: "at least one value"},
+		{name: "in long value", filter: map[string]interface{}{"a": map[string]interface{}{"$in": []string{longValue}}}, want: "exceeds"},
+		{name: "range not a pair", filter: map[string]interface{}{"a": map[string]interface{}{"$range": []int{1}}}, want: "[start, end] pair"},
+		{name: "range nil", filter: map[string]interface{}{"a": map[string]interface{}{"$range": nil}}, want: "[start, end] pair"},
+		{name: "range non-number", filter: map[string]interface{}{"a": map[string]interface{}{"$range": []interface{}{"1", 2}}}, want: "bounds must be numbers"},
+		{name: "range out of bounds", filter: map[string]interface{}{"a": map[string]interface{}{"$range": []int{0, 1000}}}, want: "bounds must be within"},
+		{name: "range reversed", filter: map[string]interface{}{"a": map[string]interface{}{"$range": [2]float64{5, 1}}}, want: "greater than end"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.filter)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Validate() = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	err := Validate(map[string]interface{}{
+		"a": map[string]interface{}{"$IN": []string{"x"}},
+		"b": map[string]interface{}{"$range": []int{5, 1}},
+	})
+	if err == nil {
+		t.Fatal("Validate() = nil, want two errors")
+	}
+	for _, want := range []string{`did you mean "$in"`, "greater than end"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate() = %v, want it to contain %q", err, want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package endee
+
+import "math"
+
+// Score normalization helpers shared by the result-merging features.
+
+// minMaxNormalize maps scores onto [0, 1]. When every score is equal each maps to 1.
+func minMaxNormalize(scores []float64) []float64 {
+	out := make([]float64, len(scores))
+	if len(scores) == 0 {
+		return out
+	}
+
+	lo, hi := scores[0], scores[0]
+	for _, s := range scores[1:] {
+		lo = math.Min(lo, s)
+		hi = math.Max(hi, s)
+	}
+
+	span := hi - lo
+	for i, s := range scores {
+		if span == 0 {
+			out[i] = 1
+		} else {
+			out[i] = (s - lo) / span
+		}
+	}
+
+	return out
+}
+
+// zScoreNormalize maps scores to standard deviations from their mean.
+// When the deviation is zero every score maps to 0.
+func zScoreNormalize(scores []float64) []float64 {
+	out := make([]float64, len(scores))
+	if len(scores) == 0 {
+		return out
+	}
+
+	var mean float64
+	for _, s := range scores {
+		mean += s
+	}
+	mean /= float64(len(scores))
+
+	var variance float64
+	for _, s := range scores {
+		variance += (s - mean) * (s - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(scores)))
+
+	for i, s := range scores {
+		if stddev > 0 {
+			out[i] = (s - mean) / stddev
+		}
+	}
+
+	return out
+}
+
+// rrfScore is the reciprocal rank fusion contribution of a zero-based rank.
+func rrfScore(rank, k int) float64 {
+	return 1 / float64(k+rank+1)
+}
+
+// similarities extracts the similarity of each result.
+func similarities(results []QueryResult) []float64 {
+	scores := make([]float64, len(results))
+	for i, r := range results {
+		scores[i] = float64(r.Similarity)
+	}
+
+	return scores
+}
+
+// dbsfNormalize maps scores onto [0, 1] using the mean ± 3 standard deviations of the
+// distribution as its bounds, clamping outliers. When the deviation is zero every score maps to 0.5.
+func dbsfNormalize(scores []float64) []float64 {
+	out := make([]float64, len(scores))
+	if len(scores) == 0 {
+		return out
+	}
+
+	var mean float64
+	for _, s := range scores {
+		mean += s
+	}
+	mean /= float64(len(scores))
+
+	var variance float64
+	for _, s := range scores {
+		variance += (s - mean) * (s - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(scores)))
+
+	lo, hi := mean-3*stddev, mean+3*stddev
+	for i, s := range scores {
+		if stddev == 0 {
+			out[i] = 0.5
+
+			continue
+		}
+		out[i] = math.Min(1, math.Max(0, (s-lo)/(hi-lo)))
+	}
+
+	return out
+}
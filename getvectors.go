@@ -0,0 +1,109 @@
+package endee
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultGetConcurrency is the number of concurrent lookups GetVectors runs when no limit is given.
+const DefaultGetConcurrency = 16
+
+// GetVectorsOptions controls GetVectors.
+type GetVectorsOptions struct {
+	Concurrency int // Maximum lookups in flight (default DefaultGetConcurrency)
+}
+
+// GetVectorsResult is the outcome of GetVectors. Every requested ID appears in exactly
+// one of Found, Missing or Errors.
+type GetVectorsResult struct {
+	Found   map[string]VectorItem // Vectors that were retrieved
+	Missing []string              // IDs the server reported as not found, in request order
+	Errors  map[string]error      // IDs whose lookup failed for any other reason
+}
+
+// GetVectors retrieves many vectors by ID. Repeated and empty IDs are dropped, and the
+// lookups run concurrently, one request per ID, since the server has no batch endpoint.
+// A not-found answer only puts an ID in Missing once the index itself is confirmed to
+// exist; if the index is missing, those IDs go to Errors and the error is returned.
+// Otherwise the returned error is non-nil only when ctx ends before every lookup has run;
+// the IDs that did not run are then reported in Errors.
+func (idx *Index) GetVectors(ctx context.Context, ids []string, opts GetVectorsOptions) (GetVectorsResult, error) {
+	ids = dedupeIDs(ids)
+	result := GetVectorsResult{
+		Found:  make(map[string]VectorItem, len(ids)),
+		Errors: make(map[string]error),
+	}
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultGetConcurrency
+	}
+	items := make([]VectorItem, len(ids))
+	errs := make([]error, len(ids))
+	next := runBounded(ctx, len(ids), concurrency, func(i int) {
+		items[i], errs[i] = idx.GetVectorWithContext(ctx, ids[i])
+	})
+
+	var cancelErr error
+	if next < len(ids) {
+		cancelErr = fmt.Errorf("get vectors cancelled: %w", ctx.Err())
+		for i := next; i < len(ids); i++ {
+			errs[i] = cancelErr
+		}
+	}
+
+	for i, id := range ids {
+		var notFound *NotFoundError
+		switch {
+		case errs[i] == nil:
+			result.Found[id] = items[i]
+		case errors.As(errs[i], &notFound):
+			result.Missing = append(result.Missing, id)
+		default:
+			result.Errors[id] = errs[i]
+		}
+	}
+
+	// The server answers 404 for a missing index as well as a missing vector
+	if len(result.Missing) > 0 {
+		if err := idx.confirmIndexExists(ctx); err != nil {
+			for _, id := range result.Missing {
+				result.Errors[id] = err
+			}
+			result.Missing = nil
+
+			return result, err
+		}
+	}
+
+	return result, cancelErr
+}
+
+// confirmIndexExists tells an index-level 404 apart from a vector-level one. It returns nil
+// when the index exists, and an error wrapping the NotFoundError when it does not.
+func (idx *Index) confirmIndexExists(ctx context.Context) error {
+	resp, err := idx.executeRequestWithContext(ctx, "GET", "index/%s/info", nil, "application/json")
+	if err != nil {
+		return fmt.Errorf("failed to confirm index %s exists: %w", idx.Name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if err := checkError(resp); err != nil {
+		var notFound *NotFoundError
+		if errors.As(err, &notFound) {
+			return fmt.Errorf("index %s does not exist: %w", idx.Name, err)
+		}
+
+		return fmt.Errorf("failed to confirm index %s exists: %w", idx.Name, err)
+	}
+
+	// Drain so the connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return nil
+}
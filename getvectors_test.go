@@ -0,0 +1,94 @@
+package endee
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// vectorStoreStub serves index/{name}/info and index/{name}/vector/get for one index
+// holding the given IDs. Every other index name answers 404, as a missing index does.
+type vectorStoreStub struct {
+	index string
+	ids   map[string]bool
+}
+
+func newVectorStoreServer(t *testing.T, index string, ids ...string) *httptest.Server {
+	t.Helper()
+
+	stub := &vectorStoreStub{index: index, ids: make(map[string]bool)}
+	for _, id := range ids {
+		stub.ids[id] = true
+	}
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func (s *vectorStoreStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/index/"), "/")
+	if name != s.index {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":"not found"}`))
+
+		return
+	}
+
+	switch action {
+	case "info":
+		_, _ = w.Write([]byte(`{}`))
+	case "vector/get":
+		var req struct {
+			ID string `json:"id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if !s.ids[req.ID] {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"not found"}`))
+
+			return
+		}
+
+		body, _ := msgpack.Marshal(&vectorTuple{ID: req.ID, Filter: "{}", Norm: 1, Vector: []float32{1, 0}})
+		_, _ = w.Write(body)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestGetVectorsReportsMissingIDs(t *testing.T) {
+	srv := newVectorStoreServer(t, "docs", "a", "c")
+	idx := &Index{Name: "docs", URL: srv.URL, Dimension: 2, SpaceType: L2}
+
+	result, err := idx.GetVectors(context.Background(), []string{"a", "b", "c", "a"}, GetVectorsOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Found) != 2 || len(result.Errors) != 0 {
+		t.Fatalf("result = %+v, want a and c found", result)
+	}
+	if len(result.Missing) != 1 || result.Missing[0] != "b" {
+		t.Fatalf("Missing = %v, want [b]", result.Missing)
+	}
+}
+
+func TestGetVectorsMissingIndexIsAnError(t *testing.T) {
+	srv := newVectorStoreServer(t, "docs", "a")
+	idx := &Index{Name: "dosc", URL: srv.URL, Dimension: 2, SpaceType: L2}
+
+	result, err := idx.GetVectors(context.Background(), []string{"a", "b"}, GetVectorsOptions{})
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) || !strings.Contains(err.Error(), "index dosc does not exist") {
+		t.Fatalf("err = %v, want the missing index reported", err)
+	}
+	if len(result.Missing) != 0 || len(result.Errors) != 2 {
+		t.Fatalf("result = %+v, want both IDs in Errors and none in Missing", result)
+	}
+}
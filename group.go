@@ -0,0 +1,126 @@
+package endee
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// GroupSource selects where QueryGrouped reads the grouping field from.
+type GroupSource int
+
+const (
+	// GroupByFilter groups by a field of each result's Filter.
+	GroupByFilter GroupSource = iota
+	// GroupByMeta groups by a field of each result's Meta.
+	GroupByMeta
+)
+
+// GroupOptions controls QueryGrouped.
+type GroupOptions struct {
+	Field        string      // Field whose value identifies a group, e.g. "doc_id"
+	Source       GroupSource // Filter (default) or Meta
+	Groups       int         // Groups to return (default DefaultTopK)
+	HitsPerGroup int         // Hits kept per group (default 1)
+}
+
+// ResultGroup is one group of hits sharing a field value, best hit first.
+type ResultGroup struct {
+	Key  string // Field value, formatted as a string
+	Hits []QueryResult
+}
+
+// GroupedResults is the outcome of QueryGrouped.
+type GroupedResults struct {
+	Groups []ResultGroup // Ordered by each group's best hit
+	// Exhausted reports that the candidates ran out before every returned group was
+	// full, either because the index had no more matches or MaxTopKAllowed was reached.
+	Exhausted bool
+	Fetched   int // Candidates examined in the final round
+}
+
+// QueryGrouped runs opts and collapses the hits into groups keyed by group.Field.
+// Candidates are over-fetched, doubling up to MaxTopKAllowed, until Groups groups hold
+// HitsPerGroup hits each. Hits without the field are skipped. opts.TopK is ignored.
+func (idx *Index) QueryGrouped(ctx context.Context, opts QueryOptions, group GroupOptions) (GroupedResults, error) {
+	if group.Field == "" {
+		return GroupedResults{}, errors.New("group field cannot be empty")
+	}
+	if opts.MMR != nil || opts.Rerank != nil {
+		return GroupedResults{}, errors.New("mmr and reranking are not supported by QueryGrouped")
+	}
+
+	groups := group.Groups
+	if groups == 0 {
+		groups = DefaultTopK
+	}
+	perGroup := group.HitsPerGroup
+	if perGroup == 0 {
+		perGroup = 1
+	}
+	if groups < 0 || perGroup < 0 {
+		return GroupedResults{}, errors.New("groups and hits per group must be positive")
+	}
+
+	fetch := min(groups*perGroup, MaxTopKAllowed)
+	for {
+		query := opts
+		query.TopK = &fetch
+
+		results, err := idx.QueryWithOptions(ctx, query)
+		if err != nil {
+			return GroupedResults{}, err
+		}
+
+		collected, filled := collectGroups(results, group.Field, group.Source, groups, perGroup)
+
+		// Fewer hits than asked for means the index has nothing more to offer
+		exhausted := len(results) < fetch || fetch == MaxTopKAllowed
+		if filled || exhausted {
+			return GroupedResults{Groups: collected, Exhausted: !filled, Fetched: len(results)}, nil
+		}
+
+		fetch = min(fetch*2, MaxTopKAllowed)
+	}
+}
+
+// collectGroups groups results in rank order and reports whether the first n groups each hold m hits.
+func collectGroups(results []QueryResult, field string, source GroupSource, n, m int) ([]ResultGroup, bool) {
+	index := make(map[string]int)
+	var groups []ResultGroup
+
+	for _, r := range results {
+		fields := r.Filter
+		if source == GroupByMeta {
+			fields = r.Meta
+		}
+		value, ok := fields[field]
+		if !ok || value == nil {
+			continue
+		}
+
+		key := fmt.Sprint(value)
+		pos, seen := index[key]
+		if !seen {
+			pos = len(groups)
+			index[key] = pos
+			groups = append(groups, ResultGroup{Key: key})
+		}
+		if len(groups[pos].Hits) < m {
+			groups[pos].Hits = append(groups[pos].Hits, r)
+		}
+	}
+
+	if len(groups) > n {
+		groups = groups[:n]
+	}
+
+	filled := len(groups) == n
+	for _, g := range groups {
+		if len(g.Hits) < m {
+			filled = false
+		}
+	}
+
+	return groups, filled
+}
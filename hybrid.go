@@ -0,0 +1,252 @@
+package endee
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DefaultHybridWeight is the dense leg's weight in the built-in fusers.
+const DefaultHybridWeight = 0.5
+
+// Fuser combines the dense and sparse rankings of a client-side hybrid query into one
+// score per ID, higher is better. Each ranking is ordered best first.
+type Fuser interface {
+	Fuse(dense, sparse []QueryResult) map[string]float64
+}
+
+// RRFFuser fuses by weighted reciprocal rank: DenseWeight/(K+rank) from the dense leg
+// plus (1-DenseWeight)/(K+rank) from the sparse leg.
+type RRFFuser struct {
+	K           int      // Rank constant (default DefaultRRFRankConstant)
+	DenseWeight *float64 // Weight of the dense leg (default DefaultHybridWeight)
+}
+
+// validate checks that the weight is within [0, 1].
+func (f RRFFuser) validate() error {
+	return validateHybridWeight("rrf dense weight", f.DenseWeight)
+}
+
+// Fuse implements Fuser.
+func (f RRFFuser) Fuse(dense, sparse []QueryResult) map[string]float64 {
+	k := f.K
+	if k <= 0 {
+		k = DefaultRRFRankConstant
+	}
+	w := hybridWeight(f.DenseWeight)
+
+	scores := make(map[string]float64, len(dense)+len(sparse))
+	for rank, r := range dense {
+		scores[r.ID] += w * rrfScore(rank, k)
+	}
+	for rank, r := range sparse {
+		scores[r.ID] += (1 - w) * rrfScore(rank, k)
+	}
+
+	return scores
+}
+
+// ConvexFuser fuses by a convex combination of min-max normalized similarities:
+// Alpha*dense + (1-Alpha)*sparse. A result missing from a leg scores 0 in it.
+type ConvexFuser struct {
+	Alpha *float64 // Weight of the dense leg (default DefaultHybridWeight)
+}
+
+// validate checks that the weight is within [0, 1].
+func (f ConvexFuser) validate() error {
+	return validateHybridWeight("convex alpha", f.Alpha)
+}
+
+// Fuse implements Fuser.
+func (f ConvexFuser) Fuse(dense, sparse []QueryResult) map[string]float64 {
+	return weightedLegSum(dense, sparse, hybridWeight(f.Alpha), minMaxNormalize)
+}
+
+// DBSFFuser fuses by distribution-based score fusion: each leg's similarities are scaled
+// onto [0, 1] using their mean ± 3 standard deviations, then summed with DenseWeight.
+type DBSFFuser struct {
+	DenseWeight *float64 // Weight of the dense leg (default DefaultHybridWeight)
+}
+
+// validate checks that the weight is within [0, 1].
+func (f DBSFFuser) validate() error {
+	return validateHybridWeight("dbsf dense weight", f.DenseWeight)
+}
+
+// Fuse implements Fuser.
+func (f DBSFFuser) Fuse(dense, sparse []QueryResult) map[string]float64 {
+	return weightedLegSum(dense, sparse, hybridWeight(f.DenseWeight), dbsfNormalize)
+}
+
+// weightedLegSum normalizes each leg and sums w*dense + (1-w)*sparse per ID.
+func weightedLegSum(dense, sparse []QueryResult, w float64, normalize func([]float64) []float64) map[string]float64 {
+	scores := make(map[string]float64, len(dense)+len(sparse))
+	for i, s := range normalize(similarities(dense)) {
+		scores[dense[i].ID] += w * s
+	}
+	for i, s := range normalize(similarities(sparse)) {
+		scores[sparse[i].ID] += (1 - w) * s
+	}
+
+	return scores
+}
+
+// hybridWeight returns *w, or DefaultHybridWeight when w is nil.
+func hybridWeight(w *float64) float64 {
+	if w == nil {
+		return DefaultHybridWeight
+	}
+
+	return *w
+}
+
+// validateHybridWeight rejects a leg weight outside [0, 1]; nil selects the default.
+func validateHybridWeight(name string, w *float64) error {
+	if w != nil && !(*w >= 0 && *w <= 1) {
+		return fmt.Errorf("%s must be between 0.0 and 1.0", name)
+	}
+
+	return nil
+}
+
+// HybridOptions controls QueryHybrid.
+type HybridOptions struct {
+	Fuser   Fuser // How the legs are combined (default RRFFuser{})
+	LegTopK int   // Results fetched per leg (default 2×TopK, capped at MaxTopKAllowed)
+}
+
+// LegScore is a result's position in one leg of a hybrid query.
+type LegScore struct {
+	Rank       int     // Zero-based position in the leg
+	Similarity float32 // Similarity the leg reported
+}
+
+// HybridResult is a fused result with the per-leg details that produced it.
+// The embedded QueryResult comes from the dense leg when the result appeared there,
+// otherwise from the sparse leg.
+type HybridResult struct {
+	QueryResult
+	Score  float64   // Fused score the result was ranked by
+	Dense  *LegScore // Nil when the dense leg did not return the result
+	Sparse *LegScore // Nil when the sparse leg did not return the result
+}
+
+// QueryHybrid runs the dense and sparse parts of opts as two concurrent queries and fuses
+// them on the client with hybrid.Fuser, instead of the server's built-in RRF.
+// opts needs both a dense and a sparse vector; MMR, re-ranking and similarity cutoffs
+// are not supported because the legs' similarities are on different scales. Each leg is
+// sent with the whole RRF weight on its own part, so opts.DenseRRFWeight is ignored.
+func (idx *Index) QueryHybrid(ctx context.Context, opts QueryOptions, hybrid HybridOptions) ([]HybridResult, error) {
+	if len(opts.Vector) == 0 || len(opts.SparseIndices) == 0 {
+		return nil, errors.New("client-side hybrid queries need both a dense vector and sparse_indices/sparse_values")
+	}
+	if opts.MMR != nil || opts.Rerank != nil || opts.MinSimilarity != nil || opts.MaxDistance != nil {
+		return nil, errors.New("mmr, reranking and similarity cutoffs are not supported with client-side hybrid fusion")
+	}
+
+	fuser := hybrid.Fuser
+	if fuser == nil {
+		fuser = RRFFuser{}
+	}
+	if v, ok := fuser.(interface{ validate() error }); ok {
+		if err := v.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	denseWeight, sparseWeight := 1.0, 0.0
+	denseOpts, sparseOpts := opts, opts
+	denseOpts.SparseIndices, denseOpts.SparseValues = nil, nil
+	denseOpts.DenseRRFWeight = &denseWeight
+	sparseOpts.Vector = nil
+	sparseOpts.DenseRRFWeight = &sparseWeight
+
+	topK := DefaultTopK
+	if opts.TopK != nil {
+		topK = *opts.TopK
+	}
+	if topK <= 0 || topK > MaxTopKAllowed {
+		return nil, fmt.Errorf("top_k must be between 1 and %d", MaxTopKAllowed)
+	}
+	legK := hybrid.LegTopK
+	if legK == 0 {
+		legK = min(2*topK, MaxTopKAllowed)
+	}
+	denseOpts.TopK, sparseOpts.TopK = &legK, &legK
+
+	// Validate both legs up front so neither request is sent for an invalid query
+	denseReq, err := idx.resolveQuery(denseOpts)
+	if err != nil {
+		return nil, err
+	}
+	sparseReq, err := idx.resolveQuery(sparseOpts)
+	if err != nil {
+		return nil, err
+	}
+	var dense, sparse []QueryResult
+	var denseErr, sparseErr error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		dense, denseErr = idx.search(ctx, denseReq)
+	}()
+	go func() {
+		defer wg.Done()
+		sparse, sparseErr = idx.search(ctx, sparseReq)
+	}()
+	wg.Wait()
+
+	if denseErr != nil {
+		return nil, fmt.Errorf("dense leg failed: %w", denseErr)
+	}
+	if sparseErr != nil {
+		return nil, fmt.Errorf("sparse leg failed: %w", sparseErr)
+	}
+
+	return fuseHybrid(dense, sparse, fuser.Fuse(dense, sparse), topK), nil
+}
+
+// fuseHybrid joins the legs by ID, attaches per-leg details and ranks by fused score.
+func fuseHybrid(dense, sparse []QueryResult, scores map[string]float64, topK int) []HybridResult {
+	byID := make(map[string]int, len(dense)+len(sparse))
+	var fused []HybridResult
+
+	for rank, r := range dense {
+		if _, seen := byID[r.ID]; seen {
+			continue
+		}
+		byID[r.ID] = len(fused)
+		fused = append(fused, HybridResult{
+			QueryResult: r,
+			Dense:       &LegScore{Rank: rank, Similarity: r.Similarity},
+		})
+	}
+	for rank, r := range sparse {
+		if pos, seen := byID[r.ID]; seen {
+			if fused[pos].Sparse == nil {
+				fused[pos].Sparse = &LegScore{Rank: rank, Similarity: r.Similarity}
+			}
+
+			continue
+		}
+		byID[r.ID] = len(fused)
+		fused = append(fused, HybridResult{
+			QueryResult: r,
+			Sparse:      &LegScore{Rank: rank, Similarity: r.Similarity},
+		})
+	}
+
+	for i := range fused {
+		fused[i].Score = scores[fused[i].ID]
+	}
+	sort.SliceStable(fused, func(a, b int) bool { return fused[a].Score > fused[b].Score })
+
+	if len(fused) > topK {
+		fused = fused[:topK]
+	}
+
+	return fused
+}
@@ -11,6 +11,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/vmihailenco/msgpack/v5"
 )
@@ -32,6 +33,23 @@ type Index struct {
 	M           int
 	EfCon       int
 	HTTP        *http.Client
+
+	limiterMu    sync.Mutex
+	learnedLimit float64
+
+	cacheMu            sync.RWMutex
+	cache              QueryCache
+	cacheStoreMu       sync.Mutex // Serializes storing results with invalidation
+	cacheGen           atomic.Uint64
+	cacheHits          atomic.Uint64
+	cacheMisses        atomic.Uint64
+	cacheInvalidations atomic.Uint64
+
+	coalesce atomic.Bool
+	flights  flightGroup
+
+	embedderMu sync.RWMutex
+	embedder   Embedder
 }
 
 // IndexParams represents the parameters passed to create an Index.
@@ -67,13 +85,19 @@ type VectorObject struct {
 
 // QueryResult represents a single search result.
 type QueryResult struct {
-	ID         string                 `json:"id"`
-	Similarity float32                `json:"similarity"`
-	Distance   float32                `json:"distance"`
-	Meta       map[string]interface{} `json:"meta"`
-	Filter     map[string]interface{} `json:"filter,omitempty"`
-	Norm       float32                `json:"norm"`
-	Vector     []float32              `json:"vector"`
+	ID          string                 `json:"id"`
+	Similarity  float32                `json:"similarity"`
+	Distance    float32                `json:"distance"`
+	Meta        map[string]interface{} `json:"meta"`
+	Filter      map[string]interface{} `json:"filter,omitempty"`
+	Norm        float32                `json:"norm"`
+	Vector      []float32              `json:"vector"`
+	MMRScore    float32                `json:"mmr_score,omitempty"`    // Set when results were diversified with MMR
+	RerankScore float32                `json:"rerank_score,omitempty"` // Set when results were reordered by a Reranker
+
+	// Undecoded meta and filter of results from QueryIter, read by DecodeMeta and DecodeFilter
+	rawMeta   []byte
+	rawFilter string
 }
 
 // FilterParams represents advanced filtering parameters for HNSW search.
@@ -179,6 +203,11 @@ func (idx *Index) executeRequestWithContext(ctx context.Context, method, path st
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	return idx.doRequest(ctx, req, contentType)
+}
+
+// doRequest sets the common headers on req and executes it.
+func (idx *Index) doRequest(ctx context.Context, req *http.Request, contentType string) (*http.Response, error) {
 	req = req.WithContext(ctx)
 	req.Header.Set("Authorization", idx.Token)
 	if contentType != "" {
@@ -232,12 +261,24 @@ func (idx *Index) normalizeVector(vector []float32) ([]float32, float32, error)
 }
 
 // Upsert inserts or updates vectors in the index.
-func (idx *Index) Upsert(inputArray []VectorItem) error {
-	return idx.UpsertWithContext(context.Background(), inputArray)
+func (idx *Index) Upsert(inputArray []VectorItem, opts ...UpsertOption) error {
+	return idx.UpsertWithContext(context.Background(), inputArray, opts...)
 }
 
 // UpsertWithContext inserts or updates vectors with context support and concurrent processing.
-func (idx *Index) UpsertWithContext(ctx context.Context, inputArray []VectorItem) error {
+func (idx *Index) UpsertWithContext(ctx context.Context, inputArray []VectorItem, opts ...UpsertOption) error {
+	cfg := newUpsertConfig(opts)
+
+	// Dry runs report every problem and never reach the server
+	if cfg.dryRun {
+		report := idx.ValidateUpsert(inputArray)
+		if cfg.dryRunReport != nil {
+			*cfg.dryRunReport = *report
+		}
+
+		return report.Err()
+	}
+
 	if len(inputArray) > MaxVectorsPerBatch {
 		return fmt.Errorf("cannot insert more than %d vectors at a time", MaxVectorsPerBatch)
 	}
@@ -246,11 +287,53 @@ func (idx *Index) UpsertWithContext(ctx context.Context, inputArray []VectorItem
 		return nil
 	}
 
+	if err := idx.validateUpsertBatch(inputArray); err != nil {
+		return err
+	}
+
+	cfg.progress = newProgressTracker(cfg.progressFn, len(inputArray))
+	defer cfg.progress.finish()
+
+	if cfg.streaming {
+		return idx.upsertStreaming(ctx, inputArray, cfg)
+	}
+
+	// For small batches, use sequential processing
+	if len(inputArray) <= 10 {
+		return idx.upsertSequential(ctx, inputArray, cfg)
+	}
+
+	// For larger batches, use concurrent processing
+	return idx.upsertConcurrent(ctx, inputArray, cfg)
+}
+
+// validateUpsertBatch runs the client-side checks applied to every upsert batch
+// and returns the first issue found.
+func (idx *Index) validateUpsertBatch(inputArray []VectorItem) error {
+	if issues := idx.collectUpsertIssues(inputArray, true); len(issues) > 0 {
+		return issues[0]
+	}
+
+	return nil
+}
+
+// collectUpsertIssues checks a batch against the index's constraints.
+// With stopAtFirst set it returns as soon as one issue is found.
+func (idx *Index) collectUpsertIssues(inputArray []VectorItem, stopAtFirst bool) []UpsertIssue {
+	var issues []UpsertIssue
+	add := func(i int, id string, format string, args ...interface{}) bool {
+		issues = append(issues, UpsertIssue{Item: i, ID: id, Message: fmt.Sprintf(format, args...)})
+
+		return stopAtFirst
+	}
+
 	// Check for duplicate IDs
 	seenIDs := make(map[string]struct{}, len(inputArray))
-	for _, item := range inputArray {
+	for i, item := range inputArray {
 		if _, exists := seenIDs[item.ID]; exists {
-			return fmt.Errorf("duplicate id found in batch: %s", item.ID)
+			if add(i, item.ID, "duplicate id found in batch: %s", item.ID) {
+				return issues
+			}
 		}
 		seenIDs[item.ID] = struct{}{}
 	}
@@ -258,13 +341,26 @@ func (idx *Index) UpsertWithContext(ctx context.Context, inputArray []VectorItem
 	// Validate each vector item
 	for i, item := range inputArray {
 		if strings.TrimSpace(item.ID) == "" {
-			return fmt.Errorf("id must not be empty (item index %d)", i)
+			if add(i, item.ID, "id must not be empty (item index %d)", i) {
+				return issues
+			}
+		}
+
+		// Check dimension against the index
+		if len(item.Vector) != idx.Dimension {
+			if add(i, item.ID, "vector dimension mismatch: expected %d, got %d (item id: %s)", idx.Dimension, len(item.Vector), item.ID) {
+				return issues
+			}
 		}
 
 		// Check for NaN / Inf in vector values
 		for j, v := range item.Vector {
 			if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
-				return fmt.Errorf("vector contains invalid value (NaN or Inf) at index %d (item id: %s)", j, item.ID)
+				if add(i, item.ID, "vector contains invalid value (NaN or Inf) at index %d (item id: %s)", j, item.ID) {
+					return issues
+				}
+
+				break
 			}
 		}
 
@@ -273,157 +369,197 @@ func (idx *Index) UpsertWithContext(ctx context.Context, inputArray []VectorItem
 		hasValues := len(item.SparseValues) > 0
 
 		if hasIndices != hasValues {
-			return fmt.Errorf("sparse_indices and sparse_values must both be provided together (item id: %s)", item.ID)
+			if add(i, item.ID, "sparse_indices and sparse_values must both be provided together (item id: %s)", item.ID) {
+				return issues
+			}
 		}
 
-		if hasIndices && len(item.SparseIndices) != len(item.SparseValues) {
-			return fmt.Errorf("sparse_indices and sparse_values must have the same length (item id: %s)", item.ID)
+		if hasIndices && hasValues && len(item.SparseIndices) != len(item.SparseValues) {
+			if add(i, item.ID, "sparse_indices and sparse_values must have the same length (item id: %s)", item.ID) {
+				return issues
+			}
 		}
 
 		// Hybrid enforcement
 		if idx.IsHybrid && (!hasIndices || !hasValues) {
-			return fmt.Errorf("hybrid index requires sparse_indices and sparse_values for every item (item id: %s)", item.ID)
+			if add(i, item.ID, "hybrid index requires sparse_indices and sparse_values for every item (item id: %s)", item.ID) {
+				return issues
+			}
 		}
 		if !idx.IsHybrid && (hasIndices || hasValues) {
-			return fmt.Errorf("sparse_indices and sparse_values cannot be used on a non-hybrid index (item id: %s)", item.ID)
+			if add(i, item.ID, "sparse_indices and sparse_values cannot be used on a non-hybrid index (item id: %s)", item.ID) {
+				return issues
+			}
 		}
-	}
 
-	// For small batches, use sequential processing
-	if len(inputArray) <= 10 {
-		return idx.upsertSequential(ctx, inputArray)
+		// Filter key/value size limits
+		for key, value := range item.Filter {
+			if len(key) > MaxKeyBytes {
+				if add(i, item.ID, "filter key %q exceeds %d bytes (item id: %s)", key, MaxKeyBytes, item.ID) {
+					return issues
+				}
+			}
+			if str, ok := value.(string); ok && len(str) > MaxValueBytes {
+				if add(i, item.ID, "filter value for key %q exceeds %d bytes (item id: %s)", key, MaxValueBytes, item.ID) {
+					return issues
+				}
+			}
+		}
 	}
 
-	// For larger batches, use concurrent processing
-	return idx.upsertConcurrent(ctx, inputArray)
+	return issues
 }
 
 // upsertSequential processes vectors sequentially for small batches.
-func (idx *Index) upsertSequential(ctx context.Context, inputArray []VectorItem) error {
+func (idx *Index) upsertSequential(ctx context.Context, inputArray []VectorItem, cfg *upsertConfig) error {
 	// Pre-allocate slice with known capacity
-	vectorBatch := make([][]interface{}, 0, len(inputArray))
+	vectorBatch := make([]vectorTuple, 0, len(inputArray))
 
 	for _, item := range inputArray {
-		// Normalize vector
-		normalizedVector, norm, err := idx.normalizeVector(item.Vector)
+		tuple, err := idx.buildVectorTuple(item)
 		if err != nil {
 			return err
 		}
 
-		// Serialize metadata using JSONZip (zlib compressed)
-		metaBytes, err := JSONZip(item.Meta)
-		if err != nil {
-			return fmt.Errorf("failed to compress metadata: %v", err)
-		}
+		vectorBatch = append(vectorBatch, tuple)
+	}
 
-		// Serialize filter
-		filterBytes, err := json.Marshal(item.Filter)
-		if err != nil {
-			return fmt.Errorf("failed to serialize filter: %v", err)
-		}
+	// Serialize data using msgpack (matching Python implementation)
+	serializedData, err := msgpack.Marshal(vectorBatch)
+	if err != nil {
+		return fmt.Errorf("failed to serialize vector batch: %w", err)
+	}
 
-		// Create vector object as array (matching Python structure)
-		vectorObj := []interface{}{
-			item.ID,             // str(item.get('id', ''))
-			metaBytes,           // meta_data (compressed bytes)
-			string(filterBytes), // json.dumps(item.get('filter', {}))
-			norm,                // float(norms[i])
-			normalizedVector,    // normalizedVector[i].tolist()
-		}
+	cfg.progress.submitted(len(inputArray), len(serializedData))
 
-		// Add sparse vectors if present and index is hybrid-capable (SparseDim > 0)
-		// Or just if sparse vectors are present in the item, assume user knows what they are doing
-		if len(item.SparseIndices) > 0 && len(item.SparseValues) > 0 {
-			vectorObj = append(vectorObj, item.SparseIndices, item.SparseValues)
-		}
+	if err := idx.sendUpsertBody(ctx, serializedData); err != nil {
+		cfg.progress.failed(len(inputArray))
 
-		vectorBatch = append(vectorBatch, vectorObj)
+		return err
 	}
 
-	// Serialize data using msgpack (matching Python implementation)
-	serializedData, err := msgpack.Marshal(vectorBatch)
+	cfg.progress.acknowledged(len(inputArray))
+
+	return nil
+}
+
+// buildVectorTuple converts an item into its wire tuple. The vector is not copied;
+// normalization is applied while encoding.
+func (idx *Index) buildVectorTuple(item VectorItem) (vectorTuple, error) {
+	// Check dimension of the vector
+	if len(item.Vector) != idx.Dimension {
+		return vectorTuple{}, fmt.Errorf("vector dimension mismatch: expected %d, got %d",
+			idx.Dimension, len(item.Vector))
+	}
+
+	// Serialize metadata using JSONZip (zlib compressed)
+	metaBytes, err := JSONZip(item.Meta)
 	if err != nil {
-		return fmt.Errorf("failed to serialize vector batch: %w", err)
+		return vectorTuple{}, fmt.Errorf("failed to compress metadata: %v", err)
 	}
 
+	// Serialize filter
+	filterBytes, err := json.Marshal(item.Filter)
+	if err != nil {
+		return vectorTuple{}, fmt.Errorf("failed to serialize filter: %v", err)
+	}
+
+	norm := idx.vectorNorm(item.Vector)
+
+	return vectorTuple{
+		ID:            item.ID,
+		Meta:          metaBytes,
+		Filter:        string(filterBytes),
+		Norm:          norm,
+		Vector:        item.Vector,
+		SparseIndices: item.SparseIndices,
+		SparseValues:  item.SparseValues,
+		scale:         norm,
+	}, nil
+}
+
+// sendUpsertBody posts an encoded msgpack vector batch to the insert endpoint.
+func (idx *Index) sendUpsertBody(ctx context.Context, body []byte) error {
+	defer idx.invalidateCache()
+
 	// Execute request using helper method with context
-	resp, err := idx.executeRequestWithContext(ctx, "POST", "index/%s/vector/insert", serializedData, "application/msgpack")
+	resp, err := idx.executeRequestWithContext(ctx, "POST", "index/%s/vector/insert", body, "application/msgpack")
 	if err != nil {
 		return err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	// Check response status
-	if err := checkError(resp); err != nil {
+	return checkError(resp)
+}
+
+// upsertConcurrent processes vectors concurrently for large batches.
+func (idx *Index) upsertConcurrent(ctx context.Context, inputArray []VectorItem, cfg *upsertConfig) error {
+	encoded, err := idx.encodeVectorItems(inputArray)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	return idx.sendEncoded(ctx, encoded, cfg)
 }
 
-// upsertConcurrent processes vectors concurrently for large batches.
-func (idx *Index) upsertConcurrent(ctx context.Context, inputArray []VectorItem) error {
-	// Determine optimal batch size and worker count
-	numWorkers := runtime.NumCPU()
-	if len(inputArray) < numWorkers*2 {
-		numWorkers = (len(inputArray) + 1) / 2
-	}
+// encodeVectorItems encodes each item into its own msgpack wire tuple.
+func (idx *Index) encodeVectorItems(inputArray []VectorItem) ([][]byte, error) {
+	encoded := make([][]byte, len(inputArray))
+	for i, item := range inputArray {
+		tuple, err := idx.buildVectorTuple(item)
+		if err != nil {
+			return nil, err
+		}
 
-	batchSize := (len(inputArray) + numWorkers - 1) / numWorkers
-	if batchSize > 100 {
-		batchSize = 100 // Limit batch size to avoid memory issues
+		encoded[i], err = msgpack.Marshal(&tuple)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize vector: %w", err)
+		}
 	}
 
-	// Channel for work distribution
-	workChan := make(chan []VectorItem, numWorkers)
-	resultChan := make(chan error, numWorkers)
+	return encoded, nil
+}
+
+// sendEncoded packs pre-encoded items into sub-batches by encoded size and sends
+// them through the index's adaptive concurrency limiter.
+func (idx *Index) sendEncoded(ctx context.Context, encoded [][]byte, cfg *upsertConfig) error {
+	batchCfg := cfg.adaptive.withDefaults()
+	limiter := idx.concurrencyLimiter(batchCfg)
+	defer idx.rememberLimit(limiter)
 
-	// Start workers
 	var wg sync.WaitGroup
-	for i := 0; i < numWorkers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for batch := range workChan {
-				if len(batch) > 0 {
-					err := idx.upsertSequential(ctx, batch)
-					resultChan <- err
-				}
-			}
-		}()
-	}
+	var errMu sync.Mutex
+	var errors []error
 
-	// Distribute work
-	go func() {
-		defer close(workChan)
-		for i := 0; i < len(inputArray); i += batchSize {
-			end := i + batchSize
-			if end > len(inputArray) {
-				end = len(inputArray)
-			}
+	batches := packEncodedBatches(encoded, batchCfg.MaxBatchBytes, batchCfg.MaxBatchItems)
+	for i, batch := range batches {
+		if err := limiter.acquire(ctx); err != nil {
+			errMu.Lock()
+			errors = append(errors, err)
+			errMu.Unlock()
 
-			select {
-			case workChan <- inputArray[i:end]:
-			case <-ctx.Done():
-				return
+			// Batches that were never sent still count toward the total
+			for _, unsent := range batches[i:] {
+				cfg.progress.failed(len(unsent.items))
 			}
-		}
-	}()
 
-	// Wait for workers to complete
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
-
-	// Collect results and check for errors
-	var errors []error
-	for err := range resultChan {
-		if err != nil {
-			errors = append(errors, err)
+			break
 		}
+
+		wg.Add(1)
+		go func(batch encodedBatch) {
+			defer wg.Done()
+			if err := idx.sendAdaptiveBatch(ctx, batch, limiter, batchCfg, cfg); err != nil {
+				errMu.Lock()
+				errors = append(errors, err)
+				errMu.Unlock()
+			}
+		}(batch)
 	}
 
+	wg.Wait()
+
 	if len(errors) > 0 {
 		return fmt.Errorf("upsert failed: %v", errors[0])
 	}
@@ -448,94 +584,40 @@ func (idx *Index) Query(vector []float32, sparseIndices []int, sparseValues []fl
 }
 
 // QueryWithContext performs vector similarity search with context support.
+// A zero denseRRFWeight or rrfRankConstant selects the default; use QueryWithOptions to send an explicit zero weight.
 func (idx *Index) QueryWithContext(ctx context.Context, vector []float32, sparseIndices []int, sparseValues []float32, k int, filter map[string]interface{}, ef int, includeVectors bool, filterParams *FilterParams, denseRRFWeight float64, rrfRankConstant int) ([]QueryResult, error) {
-	// Validate parameters
-	if k <= 0 || k > MaxTopKAllowed {
-		return nil, fmt.Errorf("top_k must be between 1 and %d", MaxTopKAllowed)
-	}
-	if ef < 0 || ef > MaxEfSearchAllowed {
-		return nil, fmt.Errorf("ef must be between 0 and %d", MaxEfSearchAllowed)
-	}
-
-	// Validate that at least one of dense or sparse is provided
-	hasDense := len(vector) > 0
-	hasSparseIndices := len(sparseIndices) > 0
-	hasSparseValues := len(sparseValues) > 0
-
-	if !hasDense && !hasSparseIndices {
-		return nil, fmt.Errorf("at least one of vector (dense) or sparse_indices/sparse_values must be provided")
+	opts := QueryOptions{
+		Vector:         vector,
+		SparseIndices:  sparseIndices,
+		SparseValues:   sparseValues,
+		TopK:           &k,
+		Ef:             &ef,
+		Filter:         filter,
+		IncludeVectors: includeVectors,
+		FilterParams:   filterParams,
 	}
 
-	// Validate sparse data consistency
-	if hasSparseIndices != hasSparseValues {
-		return nil, fmt.Errorf("sparse_indices and sparse_values must both be provided together")
+	// Zero RRF params mean "use the default" in the positional API
+	if denseRRFWeight != 0 {
+		opts.DenseRRFWeight = &denseRRFWeight
 	}
-
-	if hasSparseIndices && len(sparseIndices) != len(sparseValues) {
-		return nil, fmt.Errorf("sparse_indices and sparse_values must have the same length")
+	if rrfRankConstant != 0 {
+		opts.RRFRankConstant = &rrfRankConstant
 	}
 
-	// Enforce hybrid constraints: sparse fields forbidden on non-hybrid index
-	if !idx.IsHybrid && (hasSparseIndices || hasSparseValues) {
-		return nil, fmt.Errorf("sparse_indices and sparse_values cannot be used on a non-hybrid index")
-	}
-
-	// Validate filter parameters if provided
-	if filterParams != nil {
-		if filterParams.BoostPercentage < 0 || filterParams.BoostPercentage > 400 {
-			return nil, fmt.Errorf("filter_boost_percentage must be between 0 and 400")
-		}
-		if filterParams.PrefilterThreshold != 0 &&
-			(filterParams.PrefilterThreshold < 1000 || filterParams.PrefilterThreshold > 1000000) {
-			return nil, fmt.Errorf("prefilter_cardinality_threshold must be between 1,000 and 1,000,000")
-		}
-	}
-
-	// Apply defaults for RRF params if zero
-	if denseRRFWeight == 0 {
-		denseRRFWeight = DefaultDenseRRFWeight
-	}
-	if rrfRankConstant == 0 {
-		rrfRankConstant = DefaultRRFRankConstant
-	}
-
-	// Validate RRF params
-	if denseRRFWeight < 0.0 || denseRRFWeight > 1.0 {
-		return nil, fmt.Errorf("dense_rrf_weight must be between 0.0 and 1.0")
-	}
-	if rrfRankConstant < 1 {
-		return nil, fmt.Errorf("rrf_rank_constant must be at least 1")
-	}
-
-	// Normalize query vector
-	normalizedVector, norm, err := idx.normalizeVector(vector)
-	if err != nil {
-		return nil, err
-	}
-	originalVector := normalizedVector
-
-	// Prepare search request
-	requestData := QueryRequest{
-		Vector:          normalizedVector,
-		SparseIndices:   sparseIndices,
-		SparseValues:    sparseValues,
-		TopK:            k,
-		Ef:              ef,
-		IncludeVectors:  includeVectors,
-		FilterParams:    filterParams,
-		DenseRRFWeight:  denseRRFWeight,
-		RRFRankConstant: rrfRankConstant,
-	}
+	return idx.QueryWithOptions(ctx, opts)
+}
 
-	// Add filter if provided
-	if filter != nil {
-		filterBytes, err := json.Marshal([]map[string]interface{}{filter})
-		if err != nil {
-			return nil, fmt.Errorf("failed to serialize filter: %v", err)
-		}
-		requestData.Filter = string(filterBytes)
-	}
+// search answers a resolved query request from the query cache, an identical in-flight
+// request, or the server.
+func (idx *Index) search(ctx context.Context, requestData QueryRequest) ([]QueryResult, error) {
+	return idx.cachedSearch(requestData, func() ([]QueryResult, error) {
+		return idx.coalescedSearch(ctx, requestData)
+	})
+}
 
+// fetchSearch sends a resolved query request and decodes the results.
+func (idx *Index) fetchSearch(ctx context.Context, requestData QueryRequest) ([]QueryResult, error) {
 	// Serialize request data
 	jsonData, err := json.Marshal(requestData)
 	if err != nil {
@@ -562,15 +644,14 @@ func (idx *Index) QueryWithContext(ctx context.Context, vector []float32, sparse
 	}
 
 	// Parse msgpack response
-	var results [][]interface{}
+	// [similarity, id, meta, filter, norm, vector]
+	var results []resultTuple
 	err = msgpack.Unmarshal(buf.Bytes(), &results)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	// Process results with optimized memory allocation
-	// [similarity, id, meta, filter, norm, vector]
-	processedResults := make([]QueryResult, 0, len(results))
+	includeVectors := requestData.IncludeVectors
 
 	// For large result sets, use concurrent processing
 	if len(results) > 50 {
@@ -578,92 +659,30 @@ func (idx *Index) QueryWithContext(ctx context.Context, vector []float32, sparse
 	}
 
 	// Sequential processing for smaller result sets
-	for _, result := range results {
-		if len(result) < 5 {
+	processedResults := make([]QueryResult, 0, len(results))
+	for i := range results {
+		if results[i].fields < 5 {
 			continue // Skip malformed results
 		}
 
-		similarity := toFloat32(result[0])
-		vectorID := safeStringConvert(result[1])
-		// metaData might be string or []byte/[]uint8
-		var metaDataBytes []byte
-		if result[2] != nil {
-			switch v := result[2].(type) {
-			case string:
-				metaDataBytes = []byte(v)
-			case []byte:
-				metaDataBytes = v
-			}
-		}
-		filterStr := safeStringConvert(result[3])
-		normValue := toFloat32(result[4])
-
-		var vectorData []float32
-		if len(result) > 5 && result[5] != nil {
-			vectorInterface := result[5].([]interface{})
-			// Direct allocation instead of pooling
-			vectorData = make([]float32, len(vectorInterface))
-
-			// Convert with type safety using helper
-			for j, v := range vectorInterface {
-				vectorData[j] = toFloat32(v)
-			}
-		}
-
-		processed := QueryResult{
-			ID:         vectorID,
-			Similarity: similarity,
-			Distance:   1.0 - similarity,
-			Norm:       normValue,
-		}
-
-		// Parse metadata (placeholder for json_unzip equivalent)
-		// Parse metadata (unzip)
-		if len(metaDataBytes) > 0 {
-			if meta, err := JSONUnzip(metaDataBytes); err == nil {
-				processed.Meta = meta
-			}
-		}
-
-		// Parse filter
-		if filterStr != "" {
-			var filterMap map[string]interface{}
-			if err := json.Unmarshal([]byte(filterStr), &filterMap); err == nil {
-				processed.Filter = filterMap
-			}
-		}
-
-		// Handle vectors
-		if includeVectors && len(vectorData) > 0 {
-			processed.Vector = vectorData
-		} else {
-			processed.Vector = []float32{}
+		processed, err := idx.processResult(&results[i], includeVectors)
+		if err != nil {
+			return nil, err
 		}
-
 		processedResults = append(processedResults, processed)
 	}
 
-	// Use variables to avoid unused variable errors
-	_ = norm
-	_ = originalVector
-
 	return processedResults, nil
 }
 
 // processResultsConcurrent processes query results concurrently for large result sets.
-func (idx *Index) processResultsConcurrent(ctx context.Context, results [][]interface{}, includeVectors bool) ([]QueryResult, error) {
+func (idx *Index) processResultsConcurrent(ctx context.Context, results []resultTuple, includeVectors bool) ([]QueryResult, error) {
 	numWorkers := runtime.NumCPU()
 	if len(results) < numWorkers*2 {
 		numWorkers = (len(results) + 1) / 2
 	}
 
-	// Channel for work distribution
-	type workItem struct {
-		index  int
-		result []interface{}
-	}
-
-	workChan := make(chan workItem, numWorkers)
+	workChan := make(chan int, numWorkers)
 	resultChan := make(chan struct {
 		index int
 		data  QueryResult
@@ -676,13 +695,16 @@ func (idx *Index) processResultsConcurrent(ctx context.Context, results [][]inte
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for work := range workChan {
-				processed, err := idx.processResult(work.result, includeVectors)
+			for i := range workChan {
+				if results[i].fields < 5 {
+					continue // Skip malformed results, as the sequential path does
+				}
+				processed, err := idx.processResult(&results[i], includeVectors)
 				resultChan <- struct {
 					index int
 					data  QueryResult
 					err   error
-				}{work.index, processed, err}
+				}{i, processed, err}
 			}
 		}()
 	}
@@ -690,9 +712,9 @@ func (idx *Index) processResultsConcurrent(ctx context.Context, results [][]inte
 	// Distribute work
 	go func() {
 		defer close(workChan)
-		for i, result := range results {
+		for i := range results {
 			select {
-			case workChan <- workItem{i, result}:
+			case workChan <- i:
 			case <-ctx.Done():
 				return
 			}
@@ -707,74 +729,75 @@ func (idx *Index) processResultsConcurrent(ctx context.Context, results [][]inte
 
 	// Collect results in order
 	processedResults := make([]QueryResult, len(results))
+	received := make([]bool, len(results))
 	for r := range resultChan {
 		if r.err != nil {
 			return nil, r.err
 		}
 		processedResults[r.index] = r.data
+		received[r.index] = true
 	}
 
-	return processedResults, nil
-}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("result processing cancelled: %w", err)
+	}
 
-// processResult processes a single query result.
-func (idx *Index) processResult(result []interface{}, includeVectors bool) (QueryResult, error) {
-	if len(result) < 5 {
-		return QueryResult{}, fmt.Errorf("invalid result format: expected at least 5 elements, got %d", len(result))
-	}
-
-	similarity := toFloat32(result[0])
-	vectorID := safeStringConvert(result[1])
-	// metaData parsing
-	var metaDataBytes []byte
-	if result[2] != nil {
-		switch v := result[2].(type) {
-		case string:
-			metaDataBytes = []byte(v)
-		case []byte:
-			metaDataBytes = v
+	// Drop the slots of skipped results
+	kept := processedResults[:0]
+	for i := range processedResults {
+		if received[i] {
+			kept = append(kept, processedResults[i])
 		}
 	}
-	filterStr := safeStringConvert(result[3])
-	normValue := toFloat32(result[4])
 
-	processed := QueryResult{
-		ID:         vectorID,
-		Similarity: similarity,
-		Distance:   1.0 - similarity,
-		Norm:       normValue,
+	return kept, nil
+}
+
+// processResult processes a single query result.
+func (idx *Index) processResult(result *resultTuple, includeVectors bool) (QueryResult, error) {
+	if result.fields < 5 {
+		return QueryResult{}, fmt.Errorf("invalid result format: expected at least 5 elements, got %d", result.fields)
 	}
 
+	processed := idx.newResult(result, includeVectors)
+
 	// Parse metadata (unzip)
-	if len(metaDataBytes) > 0 {
-		if meta, err := JSONUnzip(metaDataBytes); err == nil {
+	if len(result.Meta) > 0 {
+		if meta, err := JSONUnzip(result.Meta); err == nil {
 			processed.Meta = meta
 		}
 	}
 
 	// Parse filter with pooled map
-	if filterStr != "" {
+	if result.Filter != "" {
 		filter := getMap()
-		if err := fastJSONUnmarshal([]byte(filterStr), &filter); err == nil {
+		if err := fastJSONUnmarshal([]byte(result.Filter), &filter); err == nil {
 			processed.Filter = filter
 		} else {
 			putMap(filter) // Return map to pool if parsing failed
 		}
 	}
 
+	return processed, nil
+}
+
+// newResult converts a decoded hit into a QueryResult without touching its meta or filter.
+func (idx *Index) newResult(result *resultTuple, includeVectors bool) QueryResult {
+	processed := QueryResult{
+		ID:         result.ID,
+		Similarity: result.Similarity,
+		Distance:   idx.distance(result.Similarity),
+		Norm:       result.Norm,
+	}
+
 	// Handle vectors
-	if includeVectors && len(result) > 5 && result[5] != nil {
-		vectorInterface := result[5].([]interface{})
-		vectorData := make([]float32, len(vectorInterface))
-		for j, v := range vectorInterface {
-			vectorData[j] = toFloat32(v)
-		}
-		processed.Vector = vectorData
+	if includeVectors && len(result.Vector) > 0 {
+		processed.Vector = result.Vector
 	} else {
 		processed.Vector = []float32{}
 	}
 
-	return processed, nil
+	return processed
 }
 
 // DeleteVectorByID deletes a vector by ID from the index.
@@ -784,6 +807,8 @@ func (idx *Index) DeleteVectorByID(id string) (string, error) {
 
 // DeleteVectorByIDWithContext deletes a vector by ID with context support.
 func (idx *Index) DeleteVectorByIDWithContext(ctx context.Context, id string) (string, error) {
+	defer idx.invalidateCache()
+
 	// Execute request using helper method with context
 	resp, err := idx.executeRequestWithContext(ctx, "DELETE", fmt.Sprintf("index/%s/vector/%s/delete", idx.Name, id), nil, "")
 	if err != nil {
@@ -827,6 +852,8 @@ func (idx *Index) DeleteVectorByFilterWithContext(ctx context.Context, filter ma
 		return "", fmt.Errorf("failed to marshal request data: %w", err)
 	}
 
+	defer idx.invalidateCache()
+
 	// Execute request using helper method with context
 	resp, err := idx.executeRequestWithContext(ctx, "DELETE", fmt.Sprintf("index/%s/vectors/delete", idx.Name), jsonData, "application/json")
 	if err != nil {
@@ -876,6 +903,11 @@ func (idx *Index) GetVector(id string) (VectorItem, error) {
 
 // GetVectorWithContext retrieves a vector by ID with context support.
 func (idx *Index) GetVectorWithContext(ctx context.Context, id string) (VectorItem, error) {
+	return idx.coalescedGetVector(ctx, id)
+}
+
+// fetchVector requests a vector by ID from the server.
+func (idx *Index) fetchVector(ctx context.Context, id string) (VectorItem, error) {
 	// Prepare request body with the vector ID using fast JSON
 	requestData := map[string]string{"id": id}
 	jsonData, err := fastJSONMarshal(requestData)
@@ -902,71 +934,17 @@ func (idx *Index) GetVectorWithContext(ctx context.Context, id string) (VectorIt
 		return VectorItem{}, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	// Parse msgpack response
-	var vectorObj []interface{}
-	err = msgpack.Unmarshal(buf.Bytes(), &vectorObj)
+	// Parse msgpack response: [id, meta, filter, norm, vector, (sparse_indices, sparse_values)]
+	var tuple vectorTuple
+	err = msgpack.Unmarshal(buf.Bytes(), &tuple)
 	if err != nil {
 		return VectorItem{}, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	// Ensure we have the expected array structure: [id, meta, filter, norm, vector]
-	if len(vectorObj) < 5 {
-		return VectorItem{}, fmt.Errorf("invalid response format: expected 5 elements, got %d", len(vectorObj))
-	}
-
-	// Extract data from response array
-	vectorID := safeStringConvert(vectorObj[0])
-
-	var metaDataBytes []byte
-	if vectorObj[1] != nil {
-		switch v := vectorObj[1].(type) {
-		case string:
-			metaDataBytes = []byte(v)
-		case []byte:
-			metaDataBytes = v
-		}
-	}
-
-	filterData := safeStringConvert(vectorObj[2])
-	normValue := toFloat32(vectorObj[3])
-	vectorInterface := vectorObj[4].([]interface{})
-
-	// Handle sparse data if present (elements 5 and 6)
-	var sparseIndices []int
-	var sparseValues []float32
-
-	if len(vectorObj) >= 7 {
-		// Extract sparse indices
-		if indicesInterface, ok := vectorObj[5].([]interface{}); ok {
-			sparseIndices = make([]int, len(indicesInterface))
-			for j, v := range indicesInterface {
-				if idx, ok := v.(int64); ok {
-					sparseIndices[j] = int(idx)
-				} else if idx, ok := v.(uint64); ok {
-					sparseIndices[j] = int(idx)
-				} // Add other number types if needed
-			}
-		}
-
-		// Extract sparse values
-		if valuesInterface, ok := vectorObj[6].([]interface{}); ok {
-			sparseValues = make([]float32, len(valuesInterface))
-			for j, v := range valuesInterface {
-				sparseValues[j] = toFloat32(v)
-			}
-		}
-	}
-
-	// Convert vector data with type safety
-	vector := make([]float32, len(vectorInterface))
-	for j, v := range vectorInterface {
-		vector[j] = toFloat32(v)
-	}
-
 	// Parse metadata using JSONUnzip
 	var meta map[string]interface{}
-	if len(metaDataBytes) > 0 {
-		if m, err := JSONUnzip(metaDataBytes); err == nil {
+	if len(tuple.Meta) > 0 {
+		if m, err := JSONUnzip(tuple.Meta); err == nil {
 			meta = m
 		} else {
 			meta = make(map[string]interface{})
@@ -977,9 +955,9 @@ func (idx *Index) GetVectorWithContext(ctx context.Context, id string) (VectorIt
 
 	// Parse filter using pooled map and fast JSON
 	var filter map[string]interface{}
-	if filterData != "" {
+	if tuple.Filter != "" {
 		filter = getMap()
-		if err := fastJSONUnmarshal([]byte(filterData), &filter); err != nil {
+		if err := fastJSONUnmarshal([]byte(tuple.Filter), &filter); err != nil {
 			// If parsing fails, return map to pool and create new empty map
 			putMap(filter)
 			filter = make(map[string]interface{})
@@ -988,15 +966,17 @@ func (idx *Index) GetVectorWithContext(ctx context.Context, id string) (VectorIt
 		filter = make(map[string]interface{})
 	}
 
-	// Use the norm value to avoid unused variable warnings
-	_ = normValue
+	vector := tuple.Vector
+	if vector == nil {
+		vector = []float32{}
+	}
 
 	// Return the VectorItem
 	return VectorItem{
-		ID:            vectorID,
+		ID:            tuple.ID,
 		Vector:        vector,
-		SparseIndices: sparseIndices,
-		SparseValues:  sparseValues,
+		SparseIndices: tuple.SparseIndices,
+		SparseValues:  tuple.SparseValues,
 		Meta:          meta,
 		Filter:        filter,
 	}, nil
@@ -1033,6 +1013,8 @@ func (idx *Index) UpdateFiltersWithContext(ctx context.Context, updates []Filter
 		return "", fmt.Errorf("failed to marshal request data: %w", err)
 	}
 
+	defer idx.invalidateCache()
+
 	// Execute request using helper method with context
 	resp, err := idx.executeRequestWithContext(ctx, "POST", fmt.Sprintf("index/%s/filters/update", idx.Name), jsonData, "application/json")
 	if err != nil {
@@ -1151,6 +1133,8 @@ func (idx *Index) RebuildWithContext(ctx context.Context, m, efCon *int) (map[st
 		return nil, fmt.Errorf("failed to marshal request data: %w", err)
 	}
 
+	defer idx.invalidateCache()
+
 	resp, err := idx.executeRequestWithContext(ctx, "POST", "index/%s/rebuild", jsonData, "application/json")
 	if err != nil {
 		return nil, err
@@ -1229,36 +1213,3 @@ func safeStringConvert(val interface{}) string {
 		return fmt.Sprintf("%v", v)
 	}
 }
-
-// toFloat32 safely converts an interface{} to float32.
-func toFloat32(val interface{}) float32 {
-	if val == nil {
-		return 0.0
-	}
-	switch v := val.(type) {
-	case float32:
-		return v
-	case float64:
-		return float32(v)
-	case int:
-		return float32(v)
-	case int8:
-		return float32(v)
-	case int16:
-		return float32(v)
-	case int32:
-		return float32(v)
-	case int64:
-		return float32(v)
-	case uint8:
-		return float32(v)
-	case uint16:
-		return float32(v)
-	case uint32:
-		return float32(v)
-	case uint64:
-		return float32(v)
-	default:
-		return 0.0
-	}
-}
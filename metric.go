@@ -0,0 +1,78 @@
+package endee
+
+import (
+	"errors"
+	"math"
+)
+
+// distance converts a server-reported similarity into a distance in the index's metric,
+// where smaller always means closer. The server reports Similarity as 1 minus its native
+// HNSW distance, so:
+//
+//   - cosine: Similarity is the cosine similarity; Distance is the cosine distance, 1 - Similarity.
+//   - ip: Similarity is the inner product; Distance is the negative inner product, -Similarity.
+//   - l2: Similarity is 1 - the squared Euclidean distance; Distance is the Euclidean
+//     distance, sqrt(1 - Similarity), clamped at 0 against rounding.
+func (idx *Index) distance(similarity float32) float32 {
+	switch idx.SpaceType {
+	case InnerProduct:
+		return -similarity
+	case L2:
+		return float32(math.Sqrt(math.Max(0, 1-float64(similarity))))
+	default:
+		return 1 - similarity
+	}
+}
+
+// WithMinSimilarity drops results whose Similarity is below threshold.
+func WithMinSimilarity(threshold float32) QueryOption {
+	return func(o *QueryOptions) { o.MinSimilarity = &threshold }
+}
+
+// WithMaxDistance drops results whose Distance is above threshold.
+func WithMaxDistance(threshold float32) QueryOption {
+	return func(o *QueryOptions) { o.MaxDistance = &threshold }
+}
+
+// resultCutoff drops results outside the similarity and distance thresholds of a query.
+type resultCutoff struct {
+	minSimilarity *float32
+	maxDistance   *float32
+}
+
+// newResultCutoff reads the thresholds from opts.
+func newResultCutoff(opts QueryOptions) resultCutoff {
+	return resultCutoff{minSimilarity: opts.MinSimilarity, maxDistance: opts.MaxDistance}
+}
+
+// validateCutoff rejects thresholds that no result could be compared against.
+func validateCutoff(opts QueryOptions) error {
+	if opts.MinSimilarity != nil && math.IsNaN(float64(*opts.MinSimilarity)) {
+		return errors.New("min_similarity cannot be NaN")
+	}
+	if opts.MaxDistance != nil && math.IsNaN(float64(*opts.MaxDistance)) {
+		return errors.New("max_distance cannot be NaN")
+	}
+
+	return nil
+}
+
+// apply filters results in place, keeping their order.
+func (c resultCutoff) apply(results []QueryResult) []QueryResult {
+	if c.minSimilarity == nil && c.maxDistance == nil {
+		return results
+	}
+
+	kept := results[:0]
+	for _, r := range results {
+		if c.minSimilarity != nil && r.Similarity < *c.minSimilarity {
+			continue
+		}
+		if c.maxDistance != nil && r.Distance > *c.maxDistance {
+			continue
+		}
+		kept = append(kept, r)
+	}
+
+	return kept
+}
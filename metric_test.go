@@ -0,0 +1,51 @@
+package endee
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		spaceType  string
+		similarity float32
+		want       float32
+	}{
+		{Cosine, 1, 0},
+		{Cosine, 0.25, 0.75},
+		{Cosine, -1, 2},
+		{InnerProduct, 3.5, -3.5},
+		{InnerProduct, 0, 0},
+		{InnerProduct, -2, 2},
+		{L2, 1, 0},  // Identical vectors
+		{L2, -3, 2}, // Squared distance 4
+		{L2, 0.75, 0.5},
+		{L2, 1.0000001, 0}, // Rounding above 1 clamps to zero
+	}
+
+	for _, tt := range tests {
+		idx := &Index{SpaceType: tt.spaceType}
+		got := idx.distance(tt.similarity)
+		if math.Abs(float64(got-tt.want)) > 1e-6 {
+			t.Errorf("%s distance(%v) = %v, want %v", tt.spaceType, tt.similarity, got, tt.want)
+		}
+	}
+}
+
+func TestResultCutoffUsesMetricDistance(t *testing.T) {
+	idx := &Index{SpaceType: L2}
+	maxDistance := float32(1.5)
+	cutoff := resultCutoff{maxDistance: &maxDistance}
+
+	// Euclidean distances 1, 1.5 and 2
+	results := []QueryResult{
+		{ID: "near", Distance: idx.distance(0)},
+		{ID: "edge", Distance: idx.distance(-1.25)},
+		{ID: "far", Distance: idx.distance(-3)},
+	}
+
+	kept := cutoff.apply(results)
+	if len(kept) != 2 || kept[0].ID != "near" || kept[1].ID != "edge" {
+		t.Fatalf("kept %+v, want near and edge", kept)
+	}
+}
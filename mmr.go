@@ -0,0 +1,176 @@
+package endee
+
+import (
+	"context"
+	"fmt"
+	"math"
+)
+
+// Defaults for MMR diversification.
+const (
+	DefaultMMRLambda          = 0.5 // Equal weight on relevance and novelty
+	DefaultMMRFetchMultiplier = 4   // Candidates fetched per requested result
+)
+
+// MMROptions configures maximal marginal relevance diversification.
+type MMROptions struct {
+	// Lambda weighs relevance against redundancy: 1 ranks purely by similarity to the
+	// query, 0 purely by dissimilarity to results already picked.
+	Lambda float64
+	// FetchMultiplier is how many candidates are fetched per requested result
+	// (default DefaultMMRFetchMultiplier). The candidate count is capped at MaxTopKAllowed.
+	FetchMultiplier int
+}
+
+// WithMMR diversifies results with maximal marginal relevance.
+// A fetchMultiplier of zero selects DefaultMMRFetchMultiplier.
+func WithMMR(lambda float64, fetchMultiplier int) QueryOption {
+	return func(o *QueryOptions) {
+		o.MMR = &MMROptions{Lambda: lambda, FetchMultiplier: fetchMultiplier}
+	}
+}
+
+// validate checks the MMR parameters.
+func (m *MMROptions) validate() error {
+	if m.Lambda < 0 || m.Lambda > 1 {
+		return fmt.Errorf("mmr lambda must be between 0.0 and 1.0")
+	}
+	if m.FetchMultiplier < 0 {
+		return fmt.Errorf("mmr fetch multiplier cannot be negative")
+	}
+
+	return nil
+}
+
+// searchMMR over-fetches candidates with their vectors, drops those outside cutoff and greedily
+// selects requestData.TopK of the rest. Vectors are dropped from the selected results unless
+// the caller asked for them.
+func (idx *Index) searchMMR(ctx context.Context, requestData QueryRequest, mmr MMROptions, cutoff resultCutoff) ([]QueryResult, error) {
+	k := requestData.TopK
+	includeVectors := requestData.IncludeVectors
+
+	multiplier := mmr.FetchMultiplier
+	if multiplier == 0 {
+		multiplier = DefaultMMRFetchMultiplier
+	}
+	requestData.TopK = min(k*multiplier, MaxTopKAllowed)
+	requestData.IncludeVectors = true
+
+	candidates, err := idx.search(ctx, requestData)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := idx.selectMMR(cutoff.apply(candidates), requestData.Vector, k, mmr.Lambda)
+	if !includeVectors {
+		for i := range selected {
+			selected[i].Vector = []float32{}
+		}
+	}
+
+	return selected, nil
+}
+
+// selectMMR greedily picks up to k candidates maximizing
+// lambda*relevance - (1-lambda)*max(similarity to already picked results).
+// Candidates whose score is NaN are never picked.
+func (idx *Index) selectMMR(candidates []QueryResult, query []float32, k int, lambda float64) []QueryResult {
+	k = min(k, len(candidates))
+	selected := make([]QueryResult, 0, k)
+	picked := make([]bool, len(candidates))
+
+	relevance := make([]float64, len(candidates))
+	for i, c := range candidates {
+		relevance[i] = idx.mmrRelevance(c, query)
+	}
+
+	// maxRedundancy[i] is candidate i's highest similarity to any selected result
+	maxRedundancy := make([]float64, len(candidates))
+	for i := range maxRedundancy {
+		maxRedundancy[i] = math.Inf(-1)
+	}
+
+	for len(selected) < k {
+		best, bestScore := -1, math.Inf(-1)
+		for i := range candidates {
+			if picked[i] {
+				continue
+			}
+
+			redundancy := 0.0
+			if len(selected) > 0 {
+				redundancy = maxRedundancy[i]
+			}
+			score := lambda*relevance[i] - (1-lambda)*redundancy
+			if math.IsNaN(score) {
+				continue
+			}
+			if best == -1 || score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best == -1 {
+			break
+		}
+
+		picked[best] = true
+		chosen := candidates[best]
+		chosen.MMRScore = float32(bestScore)
+		selected = append(selected, chosen)
+
+		for i, c := range candidates {
+			if !picked[i] {
+				maxRedundancy[i] = math.Max(maxRedundancy[i], idx.pairwiseSimilarity(chosen.Vector, c.Vector))
+			}
+		}
+	}
+
+	return selected
+}
+
+// mmrRelevance scores a candidate against the query on the same scale pairwiseSimilarity uses.
+// For l2 that is the negated L2 distance to the query vector; otherwise it is the dense
+// similarity the server reported. MMR queries are dense-only, so it is never a fused score.
+func (idx *Index) mmrRelevance(c QueryResult, query []float32) float64 {
+	if idx.SpaceType == L2 && len(query) > 0 && len(c.Vector) > 0 {
+		return idx.pairwiseSimilarity(query, c.Vector)
+	}
+
+	return float64(c.Similarity)
+}
+
+// pairwiseSimilarity compares two stored vectors in the index's metric, higher meaning more alike:
+// cosine similarity, the inner product, or the negated L2 distance.
+func (idx *Index) pairwiseSimilarity(a, b []float32) float64 {
+	n := min(len(a), len(b))
+
+	switch idx.SpaceType {
+	case L2:
+		var sum float64
+		for i := 0; i < n; i++ {
+			d := float64(a[i]) - float64(b[i])
+			sum += d * d
+		}
+
+		return -math.Sqrt(sum)
+	case InnerProduct:
+		var dot float64
+		for i := 0; i < n; i++ {
+			dot += float64(a[i]) * float64(b[i])
+		}
+
+		return dot
+	default:
+		var dot, normA, normB float64
+		for i := 0; i < n; i++ {
+			dot += float64(a[i]) * float64(b[i])
+			normA += float64(a[i]) * float64(a[i])
+			normB += float64(b[i]) * float64(b[i])
+		}
+		if normA == 0 || normB == 0 {
+			return 0
+		}
+
+		return dot / math.Sqrt(normA*normB)
+	}
+}
@@ -0,0 +1,59 @@
+package endee
+
+import (
+	"math"
+	"testing"
+)
+
+func TestResolveQueryRejectsMMRWithSparseVector(t *testing.T) {
+	idx := &Index{Name: "docs", Dimension: 2, SpaceType: Cosine, IsHybrid: true}
+
+	opts := NewQueryOptions(WithVector([]float32{1, 0}), WithSparseVector([]int{1}, []float32{1}), WithMMR(0.5, 2))
+	if _, err := idx.resolveQuery(opts); err == nil {
+		t.Fatal("resolveQuery accepted MMR on a hybrid query")
+	}
+
+	opts = NewQueryOptions(WithVector([]float32{1, 0}), WithMMR(0.5, 2))
+	if _, err := idx.resolveQuery(opts); err != nil {
+		t.Fatalf("resolveQuery rejected a dense MMR query on a hybrid index: %v", err)
+	}
+}
+
+func TestSelectMMRSkipsNaNScores(t *testing.T) {
+	idx := &Index{Dimension: 2, SpaceType: Cosine}
+	nan := float32(math.NaN())
+
+	candidates := []QueryResult{
+		{ID: "a", Similarity: nan, Vector: []float32{1, 0}},
+		{ID: "b", Similarity: 0.9, Vector: []float32{0, 1}},
+		{ID: "c", Similarity: nan, Vector: []float32{1, 1}},
+	}
+
+	selected := idx.selectMMR(candidates, []float32{1, 0}, 3, 0.5)
+	if len(selected) != 1 || selected[0].ID != "b" {
+		t.Fatalf("selected %+v, want only b", selected)
+	}
+
+	if got := idx.selectMMR(candidates[:1], []float32{1, 0}, 1, 0.5); len(got) != 0 {
+		t.Fatalf("selected %+v from all-NaN candidates, want none", got)
+	}
+}
+
+func TestSelectMMRL2UsesDistanceOnBothSides(t *testing.T) {
+	idx := &Index{Dimension: 2, SpaceType: L2}
+
+	// a and b are near-duplicates; c is a little farther from the query but novel
+	candidates := []QueryResult{
+		{ID: "a", Vector: []float32{1, 0}},
+		{ID: "b", Vector: []float32{1.1, 0}},
+		{ID: "c", Vector: []float32{0, 1.5}},
+	}
+
+	selected := idx.selectMMR(candidates, []float32{0, 0}, 2, 0.5)
+	if len(selected) != 2 || selected[0].ID != "a" || selected[1].ID != "c" {
+		t.Fatalf("selected %v, want a then c", []string{selected[0].ID, selected[1].ID})
+	}
+	if want := float32(0.5 * -1.0); selected[0].MMRScore != want {
+		t.Fatalf("first MMRScore = %v, want %v (lambda × negated L2 distance)", selected[0].MMRScore, want)
+	}
+}
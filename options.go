@@ -0,0 +1,59 @@
+package endee
+
+import "fmt"
+
+// UpsertOption configures optional behavior of upsert calls and bulk helpers.
+type UpsertOption func(*upsertConfig)
+
+// upsertConfig holds the resolved upsert options for a single call.
+type upsertConfig struct {
+	progressFn ProgressFunc
+	progress   *progressTracker
+	adaptive   AdaptiveBatchConfig
+	streaming  bool
+
+	dryRun       bool
+	dryRunReport *UpsertValidationReport
+}
+
+// newUpsertConfig applies opts over the defaults.
+func newUpsertConfig(opts []UpsertOption) *upsertConfig {
+	cfg := &upsertConfig{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cfg)
+		}
+	}
+
+	return cfg
+}
+
+// rejectDryRunAndStreaming fails when WithDryRun or WithStreaming was given to an entry point
+// that sends pre-encoded batches and so cannot honor them. target names that entry point.
+func (cfg *upsertConfig) rejectDryRunAndStreaming(target string) error {
+	if cfg.dryRun {
+		return fmt.Errorf("WithDryRun is not supported for %s; call ValidateUpsert instead", target)
+	}
+	if cfg.streaming {
+		return fmt.Errorf("WithStreaming is not supported for %s", target)
+	}
+
+	return nil
+}
+
+// WithProgress registers fn to receive progress events while the upsert runs.
+// Calls to fn are serialized, so it does not need to be safe for concurrent use.
+func WithProgress(fn ProgressFunc) UpsertOption {
+	return func(cfg *upsertConfig) {
+		cfg.progressFn = fn
+	}
+}
+
+// WithDryRun makes the upsert validate and measure the batch without sending anything.
+// The full report is copied into report when it is non-nil; the call returns report.Err().
+func WithDryRun(report *UpsertValidationReport) UpsertOption {
+	return func(cfg *upsertConfig) {
+		cfg.dryRun = true
+		cfg.dryRunReport = report
+	}
+}
@@ -0,0 +1,152 @@
+package endee
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// PreparedBatch is an upsert batch that has been validated, normalized, compressed and
+// msgpack-encoded once, so it can be written to several indexes without repeating that work.
+type PreparedBatch struct {
+	encoded   [][]byte
+	ids       []string
+	source    string
+	dimension int
+	spaceType string
+	isHybrid  bool
+}
+
+// FanOutResult reports the outcome of sending a PreparedBatch to one target index.
+type FanOutResult struct {
+	Index string
+	Err   error
+}
+
+// PrepareUpsert validates and encodes inputArray for this index and any compatible one.
+func (idx *Index) PrepareUpsert(inputArray []VectorItem) (*PreparedBatch, error) {
+	if len(inputArray) > MaxVectorsPerBatch {
+		return nil, fmt.Errorf("cannot insert more than %d vectors at a time", MaxVectorsPerBatch)
+	}
+
+	if err := idx.validateUpsertBatch(inputArray); err != nil {
+		return nil, err
+	}
+
+	encoded, err := idx.encodeVectorItems(inputArray)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(inputArray))
+	for i, item := range inputArray {
+		ids[i] = item.ID
+	}
+
+	return &PreparedBatch{
+		encoded:   encoded,
+		ids:       ids,
+		source:    idx.Name,
+		dimension: idx.Dimension,
+		spaceType: idx.SpaceType,
+		isHybrid:  idx.IsHybrid,
+	}, nil
+}
+
+// Len returns the number of vectors in the batch.
+func (pb *PreparedBatch) Len() int {
+	return len(pb.encoded)
+}
+
+// IDs returns the vector IDs in the batch, in their original order.
+func (pb *PreparedBatch) IDs() []string {
+	ids := make([]string, len(pb.ids))
+	copy(ids, pb.ids)
+
+	return ids
+}
+
+// CompatibleWith reports whether the encoded batch is valid for target.
+// Targets must share the dimension and hybrid mode of the index that prepared the batch,
+// and must normalize vectors the same way (cosine, or a non-cosine space).
+func (pb *PreparedBatch) CompatibleWith(target *Index) error {
+	if target == nil {
+		return fmt.Errorf("target index cannot be nil")
+	}
+	if target.Dimension != pb.dimension {
+		return fmt.Errorf("index %s has dimension %d, batch was prepared for %d", target.Name, target.Dimension, pb.dimension)
+	}
+	if target.IsHybrid != pb.isHybrid {
+		return fmt.Errorf("index %s hybrid mode (%v) does not match batch prepared on %s (%v)", target.Name, target.IsHybrid, pb.source, pb.isHybrid)
+	}
+	if (target.SpaceType == Cosine) != (pb.spaceType == Cosine) {
+		return fmt.Errorf("index %s space type %s is incompatible with batch prepared for %s", target.Name, target.SpaceType, pb.spaceType)
+	}
+
+	return nil
+}
+
+// Upsert sends the prepared batch to a single target index.
+// WithDryRun and WithStreaming do not apply to an already encoded batch and are rejected.
+func (pb *PreparedBatch) Upsert(ctx context.Context, target *Index, opts ...UpsertOption) error {
+	cfg := newUpsertConfig(opts)
+	if err := cfg.rejectDryRunAndStreaming("a prepared batch"); err != nil {
+		return err
+	}
+
+	cfg.progress = newProgressTracker(cfg.progressFn, len(pb.encoded))
+	defer cfg.progress.finish()
+
+	return pb.send(ctx, target, cfg)
+}
+
+// UpsertTo sends the prepared batch to every target concurrently.
+// Results are returned in the order of targets. Options apply to each target, except
+// that a WithProgress callback receives one combined stream, totalling Len() vectors
+// per target, and is never called concurrently.
+func (pb *PreparedBatch) UpsertTo(ctx context.Context, targets []*Index, opts ...UpsertOption) []FanOutResult {
+	results := make([]FanOutResult, len(targets))
+	for i, target := range targets {
+		if target != nil {
+			results[i].Index = target.Name
+		}
+	}
+
+	cfg := newUpsertConfig(opts)
+	if err := cfg.rejectDryRunAndStreaming("a prepared batch"); err != nil {
+		for i := range results {
+			results[i].Err = err
+		}
+
+		return results
+	}
+
+	cfg.progress = newProgressTracker(cfg.progressFn, len(pb.encoded)*len(targets))
+	defer cfg.progress.finish()
+
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target *Index) {
+			defer wg.Done()
+			results[i].Err = pb.send(ctx, target, cfg)
+		}(i, target)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// send writes the batch to target using an already resolved config.
+func (pb *PreparedBatch) send(ctx context.Context, target *Index, cfg *upsertConfig) error {
+	if err := pb.CompatibleWith(target); err != nil {
+		cfg.progress.failed(len(pb.encoded))
+
+		return err
+	}
+	if len(pb.encoded) == 0 {
+		return nil
+	}
+
+	return target.sendEncoded(ctx, pb.encoded, cfg)
+}
@@ -0,0 +1,173 @@
+package endee
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProgressEvent is a snapshot of a running upsert or bulk ingest.
+type ProgressEvent struct {
+	Total        int           // Vectors expected in total, or 0 when unknown
+	Submitted    int           // Vectors sent to the server, counted once however often retried
+	Acknowledged int           // Vectors the server accepted
+	Failed       int           // Vectors in batches that failed permanently
+	Retried      int           // Vectors re-sent after a retryable failure
+	BytesSent    int64         // Request body bytes sent, including retries
+	Elapsed      time.Duration // Time since the operation started
+	Throughput   float64       // Acknowledged vectors per second
+	ETA          time.Duration // Estimated time remaining, or 0 when Total is unknown
+	Done         bool          // Set on the final event
+}
+
+// ProgressFunc receives progress events.
+type ProgressFunc func(ProgressEvent)
+
+// progressTracker accumulates counters and forwards snapshots to a ProgressFunc.
+// A nil tracker is valid and ignores all updates.
+type progressTracker struct {
+	fn    ProgressFunc
+	start time.Time
+
+	mu    sync.Mutex
+	state ProgressEvent
+}
+
+// newProgressTracker returns a tracker for fn, or nil when fn is nil.
+func newProgressTracker(fn ProgressFunc, total int) *progressTracker {
+	if fn == nil {
+		return nil
+	}
+
+	return &progressTracker{
+		fn:    fn,
+		start: time.Now(),
+		state: ProgressEvent{Total: total},
+	}
+}
+
+// update applies change to the counters and emits the new snapshot.
+func (t *progressTracker) update(change func(*ProgressEvent)) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	change(&t.state)
+
+	event := t.state
+	event.Elapsed = time.Since(t.start)
+	if secs := event.Elapsed.Seconds(); secs > 0 {
+		event.Throughput = float64(event.Acknowledged) / secs
+	}
+	if event.Total > 0 && event.Throughput > 0 {
+		remaining := event.Total - event.Acknowledged - event.Failed
+		if remaining > 0 {
+			event.ETA = time.Duration(float64(remaining) / event.Throughput * float64(time.Second))
+		}
+	}
+
+	t.fn(event)
+}
+
+// submitted records a batch of n vectors sent with the given body size.
+func (t *progressTracker) submitted(n int, bytes int) {
+	t.update(func(e *ProgressEvent) {
+		e.Submitted += n
+		e.BytesSent += int64(bytes)
+	})
+}
+
+// sent records body bytes of a re-sent batch whose vectors were already submitted.
+func (t *progressTracker) sent(bytes int) {
+	t.update(func(e *ProgressEvent) { e.BytesSent += int64(bytes) })
+}
+
+// acknowledged records n vectors accepted by the server.
+func (t *progressTracker) acknowledged(n int) {
+	t.update(func(e *ProgressEvent) { e.Acknowledged += n })
+}
+
+// failed records n vectors that will not be retried.
+func (t *progressTracker) failed(n int) {
+	t.update(func(e *ProgressEvent) { e.Failed += n })
+}
+
+// retried records n vectors about to be re-sent.
+func (t *progressTracker) retried(n int) {
+	t.update(func(e *ProgressEvent) { e.Retried += n })
+}
+
+// finish emits the final event.
+func (t *progressTracker) finish() {
+	t.update(func(e *ProgressEvent) { e.Done = true })
+}
+
+// NewProgressBar returns a ProgressFunc that draws a single-line progress bar on w, typically os.Stderr.
+func NewProgressBar(w io.Writer) ProgressFunc {
+	const width = 30
+
+	return func(e ProgressEvent) {
+		var line string
+		if e.Total > 0 {
+			done := e.Acknowledged + e.Failed
+			filled := done * width / e.Total
+			if filled > width {
+				filled = width
+			}
+			line = fmt.Sprintf("\r[%s%s] %3d%% %d/%d vectors, %.0f vec/s, ETA %s",
+				strings.Repeat("=", filled), strings.Repeat(" ", width-filled),
+				done*100/e.Total, done, e.Total, e.Throughput, e.ETA.Round(time.Second))
+		} else {
+			line = fmt.Sprintf("\r%d vectors, %.0f vec/s", e.Acknowledged+e.Failed, e.Throughput)
+		}
+		if e.Failed > 0 {
+			line += fmt.Sprintf(", %d failed", e.Failed)
+		}
+		if e.Done {
+			line += "\n"
+		}
+
+		_, _ = io.WriteString(w, line)
+	}
+}
+
+// NewSlogProgressReporter returns a ProgressFunc that logs progress to logger at most once per interval.
+// The final event is always logged.
+func NewSlogProgressReporter(logger *slog.Logger, interval time.Duration) ProgressFunc {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	var last time.Time
+
+	return func(e ProgressEvent) {
+		now := time.Now()
+		if !e.Done && now.Sub(last) < interval {
+			return
+		}
+		last = now
+
+		msg := "upsert progress"
+		if e.Done {
+			msg = "upsert finished"
+		}
+
+		logger.Info(msg,
+			slog.Int("total", e.Total),
+			slog.Int("submitted", e.Submitted),
+			slog.Int("acknowledged", e.Acknowledged),
+			slog.Int("failed", e.Failed),
+			slog.Int("retried", e.Retried),
+			slog.Int64("bytes_sent", e.BytesSent),
+			slog.Float64("vectors_per_sec", e.Throughput),
+			slog.Duration("eta", e.ETA),
+			slog.Duration("elapsed", e.Elapsed),
+		)
+	}
+}
@@ -0,0 +1,275 @@
+package endee
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// QueryOptions describes a similarity search.
+//
+// Pointer fields are optional: nil selects the default, so explicit zero values such as a
+// dense RRF weight of 0 (pure sparse ranking) can be expressed. Build it directly or with
+// NewQueryOptions and the With* options.
+type QueryOptions struct {
+	Vector          []float32              // Dense query vector; must match the index dimension
+	SparseIndices   []int                  // Sparse query indices (hybrid indexes only)
+	SparseValues    []float32              // Sparse query values (hybrid indexes only)
+	TopK            *int                   // Results to return (default DefaultTopK)
+	Ef              *int                   // Search-time candidate list size (default DefaultEfSearch)
+	Filter          map[string]interface{} // Filter conditions, combined with AND
+	IncludeVectors  bool                   // Return stored vectors with each result
+	FilterParams    *FilterParams          // Advanced filtered-search tuning
+	DenseRRFWeight  *float64               // RRF weight of the dense leg (default DefaultDenseRRFWeight)
+	RRFRankConstant *int                   // RRF rank constant (default DefaultRRFRankConstant)
+	MMR             *MMROptions            // Diversify results with maximal marginal relevance
+	Rerank          *RerankOptions         // Reorder over-fetched candidates with a Reranker
+	QueryText       string                 // Query text passed to the reranker
+	MinSimilarity   *float32               // Drop results with a lower Similarity
+	MaxDistance     *float32               // Drop results with a higher Distance
+
+	filterErr error // Set by WithFilterBuilder when the filter failed to build
+}
+
+// FilterBuilder produces a validated filter map. The filter subpackage's Filter implements it.
+type FilterBuilder interface {
+	Build() (map[string]interface{}, error)
+}
+
+// QueryOption sets a field of QueryOptions.
+type QueryOption func(*QueryOptions)
+
+// NewQueryOptions builds QueryOptions from opts.
+func NewQueryOptions(opts ...QueryOption) QueryOptions {
+	var o QueryOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	return o
+}
+
+// WithVector sets the dense query vector.
+func WithVector(vector []float32) QueryOption {
+	return func(o *QueryOptions) { o.Vector = vector }
+}
+
+// WithSparseVector sets the sparse query vector for hybrid indexes.
+func WithSparseVector(indices []int, values []float32) QueryOption {
+	return func(o *QueryOptions) {
+		o.SparseIndices = indices
+		o.SparseValues = values
+	}
+}
+
+// WithTopK sets the number of results to return.
+func WithTopK(k int) QueryOption {
+	return func(o *QueryOptions) { o.TopK = &k }
+}
+
+// WithEf sets the search-time candidate list size.
+func WithEf(ef int) QueryOption {
+	return func(o *QueryOptions) { o.Ef = &ef }
+}
+
+// WithFilter sets the filter conditions.
+func WithFilter(filter map[string]interface{}) QueryOption {
+	return func(o *QueryOptions) { o.Filter = filter }
+}
+
+// WithFilterBuilder sets the filter from a builder such as filter.And(...).
+// A build error is returned by the query before any request is made.
+func WithFilterBuilder(builder FilterBuilder) QueryOption {
+	return func(o *QueryOptions) {
+		o.Filter, o.filterErr = builder.Build()
+	}
+}
+
+// WithIncludeVectors requests stored vectors with each result.
+func WithIncludeVectors() QueryOption {
+	return func(o *QueryOptions) { o.IncludeVectors = true }
+}
+
+// WithFilterParams sets advanced filtered-search parameters.
+func WithFilterParams(params FilterParams) QueryOption {
+	return func(o *QueryOptions) { o.FilterParams = &params }
+}
+
+// WithDenseRRFWeight sets the RRF weight of the dense leg; 0 ranks by the sparse leg only.
+func WithDenseRRFWeight(weight float64) QueryOption {
+	return func(o *QueryOptions) { o.DenseRRFWeight = &weight }
+}
+
+// WithRRFRankConstant sets the RRF rank constant.
+func WithRRFRankConstant(constant int) QueryOption {
+	return func(o *QueryOptions) { o.RRFRankConstant = &constant }
+}
+
+// QueryWithOptions performs a similarity search described by opts.
+func (idx *Index) QueryWithOptions(ctx context.Context, opts QueryOptions) ([]QueryResult, error) {
+	requestData, err := idx.resolveQuery(opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Rerank == nil {
+		return idx.runQuery(ctx, requestData, opts)
+	}
+
+	k := requestData.TopK
+	multiplier := opts.Rerank.FetchMultiplier
+	if multiplier == 0 {
+		multiplier = DefaultRerankFetchMultiplier
+	}
+	requestData.TopK = min(k*multiplier, MaxTopKAllowed)
+
+	candidates, err := idx.runQuery(ctx, requestData, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return applyRerank(ctx, *opts.Rerank, RerankQuery{Text: opts.QueryText, Vector: opts.Vector}, candidates, k), nil
+}
+
+// runQuery executes a resolved request, applying the similarity and distance cutoffs
+// and diversifying the results when MMR is requested.
+func (idx *Index) runQuery(ctx context.Context, requestData QueryRequest, opts QueryOptions) ([]QueryResult, error) {
+	cutoff := newResultCutoff(opts)
+	if opts.MMR != nil {
+		return idx.searchMMR(ctx, requestData, *opts.MMR, cutoff)
+	}
+
+	results, err := idx.search(ctx, requestData)
+	if err != nil {
+		return nil, err
+	}
+
+	return cutoff.apply(results), nil
+}
+
+// resolveQuery validates opts against the index, applies defaults and normalizes the
+// dense vector. It is the single validation path for every query entry point.
+func (idx *Index) resolveQuery(opts QueryOptions) (QueryRequest, error) {
+	if opts.filterErr != nil {
+		return QueryRequest{}, fmt.Errorf("invalid filter: %w", opts.filterErr)
+	}
+
+	k := DefaultTopK
+	if opts.TopK != nil {
+		k = *opts.TopK
+	}
+	ef := DefaultEfSearch
+	if opts.Ef != nil {
+		ef = *opts.Ef
+	}
+	denseRRFWeight := DefaultDenseRRFWeight
+	if opts.DenseRRFWeight != nil {
+		denseRRFWeight = *opts.DenseRRFWeight
+	}
+	rrfRankConstant := DefaultRRFRankConstant
+	if opts.RRFRankConstant != nil {
+		rrfRankConstant = *opts.RRFRankConstant
+	}
+
+	// Validate parameters
+	if k <= 0 || k > MaxTopKAllowed {
+		return QueryRequest{}, fmt.Errorf("top_k must be between 1 and %d", MaxTopKAllowed)
+	}
+	if ef < 0 || ef > MaxEfSearchAllowed {
+		return QueryRequest{}, fmt.Errorf("ef must be between 0 and %d", MaxEfSearchAllowed)
+	}
+
+	// Validate that at least one of dense or sparse is provided
+	hasDense := len(opts.Vector) > 0
+	hasSparseIndices := len(opts.SparseIndices) > 0
+	hasSparseValues := len(opts.SparseValues) > 0
+
+	if !hasDense && !hasSparseIndices {
+		return QueryRequest{}, fmt.Errorf("at least one of vector (dense) or sparse_indices/sparse_values must be provided")
+	}
+
+	// Validate sparse data consistency
+	if hasSparseIndices != hasSparseValues {
+		return QueryRequest{}, fmt.Errorf("sparse_indices and sparse_values must both be provided together")
+	}
+
+	if hasSparseIndices && len(opts.SparseIndices) != len(opts.SparseValues) {
+		return QueryRequest{}, fmt.Errorf("sparse_indices and sparse_values must have the same length")
+	}
+
+	// Enforce hybrid constraints: sparse fields forbidden on non-hybrid index
+	if !idx.IsHybrid && (hasSparseIndices || hasSparseValues) {
+		return QueryRequest{}, fmt.Errorf("sparse_indices and sparse_values cannot be used on a non-hybrid index")
+	}
+
+	// Validate filter parameters if provided
+	if opts.FilterParams != nil {
+		if opts.FilterParams.BoostPercentage < 0 || opts.FilterParams.BoostPercentage > 400 {
+			return QueryRequest{}, fmt.Errorf("filter_boost_percentage must be between 0 and 400")
+		}
+		if opts.FilterParams.PrefilterThreshold != 0 &&
+			(opts.FilterParams.PrefilterThreshold < 1000 || opts.FilterParams.PrefilterThreshold > 1000000) {
+			return QueryRequest{}, fmt.Errorf("prefilter_cardinality_threshold must be between 1,000 and 1,000,000")
+		}
+	}
+
+	// Validate RRF params
+	if denseRRFWeight < 0.0 || denseRRFWeight > 1.0 {
+		return QueryRequest{}, fmt.Errorf("dense_rrf_weight must be between 0.0 and 1.0")
+	}
+	if rrfRankConstant < 1 {
+		return QueryRequest{}, fmt.Errorf("rrf_rank_constant must be at least 1")
+	}
+	if opts.MMR != nil {
+		if err := opts.MMR.validate(); err != nil {
+			return QueryRequest{}, err
+		}
+		// Fused hybrid scores are not on the dense scale MMR measures redundancy with
+		if hasSparseIndices {
+			return QueryRequest{}, fmt.Errorf("mmr is not supported for queries with a sparse vector")
+		}
+	}
+	if opts.Rerank != nil {
+		if err := opts.Rerank.validate(); err != nil {
+			return QueryRequest{}, err
+		}
+	}
+	if err := validateCutoff(opts); err != nil {
+		return QueryRequest{}, err
+	}
+
+	// Normalize query vector; sparse-only queries carry no dense component
+	var normalizedVector []float32
+	if hasDense {
+		var err error
+		normalizedVector, _, err = idx.normalizeVector(opts.Vector)
+		if err != nil {
+			return QueryRequest{}, err
+		}
+	}
+
+	// Prepare search request
+	requestData := QueryRequest{
+		Vector:          normalizedVector,
+		SparseIndices:   opts.SparseIndices,
+		SparseValues:    opts.SparseValues,
+		TopK:            k,
+		Ef:              ef,
+		IncludeVectors:  opts.IncludeVectors,
+		FilterParams:    opts.FilterParams,
+		DenseRRFWeight:  denseRRFWeight,
+		RRFRankConstant: rrfRankConstant,
+	}
+
+	// Add filter if provided
+	if opts.Filter != nil {
+		filterBytes, err := json.Marshal([]map[string]interface{}{opts.Filter})
+		if err != nil {
+			return QueryRequest{}, fmt.Errorf("failed to serialize filter: %v", err)
+		}
+		requestData.Filter = string(filterBytes)
+	}
+
+	return requestData, nil
+}
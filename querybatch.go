@@ -0,0 +1,92 @@
+package endee
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// BatchOptions controls QueryBatch.
+type BatchOptions struct {
+	Concurrency int          // Maximum queries in flight (default runtime.NumCPU())
+	RateLimiter *RateLimiter // Optional limiter shared with other callers; nil means unlimited
+}
+
+// BatchQueryResult holds the outcome of one query in a batch.
+type BatchQueryResult struct {
+	Results []QueryResult
+	Err     error
+}
+
+// QueryBatch runs many queries with bounded concurrency. The returned slice is aligned
+// with queries; a failing query sets its own Err without aborting the rest.
+// The returned error is non-nil only when ctx ends before every query has run,
+// in which case the queries that did not run carry ctx's error.
+func (idx *Index) QueryBatch(ctx context.Context, queries []QueryOptions, opts BatchOptions) ([]BatchQueryResult, error) {
+	results := make([]BatchQueryResult, len(queries))
+	if len(queries) == 0 {
+		return results, nil
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+
+	// Each query goes through QueryWithOptions, so validation, caching and any
+	// future multi-query transport apply to batch members exactly as to single calls
+	next := runBounded(ctx, len(queries), concurrency, func(i int) {
+		if opts.RateLimiter != nil {
+			if err := opts.RateLimiter.Wait(ctx); err != nil {
+				results[i].Err = err
+
+				return
+			}
+		}
+		results[i].Results, results[i].Err = idx.QueryWithOptions(ctx, queries[i])
+	})
+
+	if next < len(queries) {
+		err := fmt.Errorf("query batch cancelled: %w", ctx.Err())
+		for i := next; i < len(queries); i++ {
+			results[i].Err = err
+		}
+
+		return results, err
+	}
+
+	return results, nil
+}
+
+// runBounded calls fn for indexes 0..n-1 on at most concurrency goroutines and waits for them.
+// It stops handing out indexes when ctx is done and returns how many were handed out.
+func runBounded(ctx context.Context, n, concurrency int, fn func(i int)) int {
+	concurrency = min(concurrency, n)
+
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				fn(i)
+			}
+		}()
+	}
+
+	next := 0
+dispatch:
+	for ; next < n; next++ {
+		select {
+		case work <- next:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(work)
+	wg.Wait()
+
+	return next
+}
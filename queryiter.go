@@ -0,0 +1,124 @@
+package endee
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// QueryIter performs a similarity search and yields results as they are decoded from the
+// response stream, so a caller that stops early never decodes the rest.
+//
+// Meta and Filter are left nil on yielded results; call DecodeMeta and DecodeFilter for the
+// results that need them. Similarity and distance cutoffs are applied as results arrive.
+// MMR and re-ranking need the whole result set and are rejected. A failure is yielded once
+// as a non-nil error, after which the sequence ends.
+func (idx *Index) QueryIter(ctx context.Context, opts QueryOptions) iter.Seq2[QueryResult, error] {
+	return func(yield func(QueryResult, error) bool) {
+		if opts.MMR != nil || opts.Rerank != nil {
+			yield(QueryResult{}, errors.New("mmr and reranking are not supported by QueryIter"))
+
+			return
+		}
+
+		requestData, err := idx.resolveQuery(opts)
+		if err != nil {
+			yield(QueryResult{}, err)
+
+			return
+		}
+
+		jsonData, err := json.Marshal(requestData)
+		if err != nil {
+			yield(QueryResult{}, fmt.Errorf("failed to marshal request data: %w", err))
+
+			return
+		}
+
+		resp, err := idx.executeRequestWithContext(ctx, "POST", "index/%s/search", jsonData, "application/json")
+		if err != nil {
+			yield(QueryResult{}, err)
+
+			return
+		}
+		defer func() { _ = resp.Body.Close() }()
+
+		if err := checkError(resp); err != nil {
+			yield(QueryResult{}, err)
+
+			return
+		}
+
+		dec := msgpack.NewDecoder(resp.Body)
+		n, err := dec.DecodeArrayLen()
+		if err != nil {
+			yield(QueryResult{}, fmt.Errorf("failed to unmarshal response: %w", err))
+
+			return
+		}
+
+		cutoff := newResultCutoff(opts)
+		for i := 0; i < n; i++ {
+			var tuple resultTuple
+			if err := dec.Decode(&tuple); err != nil {
+				yield(QueryResult{}, fmt.Errorf("failed to unmarshal response: %w", err))
+
+				return
+			}
+			if tuple.fields < 5 {
+				continue // Skip malformed results
+			}
+
+			result := idx.newResult(&tuple, requestData.IncludeVectors)
+			result.rawMeta = tuple.Meta
+			result.rawFilter = tuple.Filter
+			if len(cutoff.apply([]QueryResult{result})) == 0 {
+				continue
+			}
+
+			if !yield(result, nil) {
+				return
+			}
+		}
+	}
+}
+
+// DecodeMeta returns the result's metadata, decoding it first for results from QueryIter.
+// Unlike eager decoding, corrupt metadata is reported as an error.
+func (r *QueryResult) DecodeMeta() (map[string]interface{}, error) {
+	if len(r.rawMeta) > 0 {
+		meta, err := JSONUnzip(r.rawMeta)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode meta of result %s: %w", r.ID, err)
+		}
+		r.Meta = meta
+		r.rawMeta = nil
+	}
+	if r.Meta == nil {
+		r.Meta = make(map[string]interface{})
+	}
+
+	return r.Meta, nil
+}
+
+// DecodeFilter returns the result's filter fields, decoding them first for results from QueryIter.
+// Unlike eager decoding, a corrupt filter is reported as an error.
+func (r *QueryResult) DecodeFilter() (map[string]interface{}, error) {
+	if r.rawFilter != "" {
+		var filter map[string]interface{}
+		if err := json.Unmarshal([]byte(r.rawFilter), &filter); err != nil {
+			return nil, fmt.Errorf("failed to decode filter of result %s: %w", r.ID, err)
+		}
+		r.Filter = filter
+		r.rawFilter = ""
+	}
+	if r.Filter == nil {
+		r.Filter = make(map[string]interface{})
+	}
+
+	return r.Filter, nil
+}
@@ -0,0 +1,263 @@
+package endee
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Default Rocchio weights for RecommendRocchio.
+const (
+	DefaultRocchioBeta  = 0.75 // Weight of the positive centroid
+	DefaultRocchioGamma = 0.15 // Weight of the negative centroid
+)
+
+// RecommendStrategy selects how Recommend turns seed vectors into a ranking.
+type RecommendStrategy int
+
+const (
+	// RecommendMean queries with mean(positives). Negative examples only keep their
+	// own IDs out of the results; use RecommendRocchio or RecommendRRF to rank away from them.
+	RecommendMean RecommendStrategy = iota
+	// RecommendRocchio queries with Beta*mean(positives) - Gamma*mean(negatives).
+	RecommendRocchio
+	// RecommendRRF queries once per example and fuses the rankings with reciprocal rank
+	// fusion; results near negative examples lose the RRF score of that ranking.
+	RecommendRRF
+)
+
+// RecommendOptions controls Recommend.
+type RecommendOptions struct {
+	// Query supplies TopK, Ef, Filter, FilterParams and IncludeVectors exactly as for
+	// QueryWithOptions. Its vector fields are ignored.
+	Query    QueryOptions
+	Strategy RecommendStrategy
+	Beta     *float64 // Rocchio positive weight (default DefaultRocchioBeta)
+	Gamma    *float64 // Rocchio negative weight (default DefaultRocchioGamma)
+	RRFK     int      // Rank constant for fusing RecommendRRF rankings (default DefaultRRFRankConstant)
+}
+
+// Recommend finds vectors similar to the positive examples and dissimilar to the negative
+// ones. Seed vectors are fetched with at most DefaultGetConcurrency requests in flight
+// and never appear in the results.
+// With RecommendRRF, results are ordered by fused rank and keep the similarity reported
+// by the first ranking they appeared in.
+func (idx *Index) Recommend(ctx context.Context, positiveIDs, negativeIDs []string, opts RecommendOptions) ([]QueryResult, error) {
+	positiveIDs = dedupeIDs(positiveIDs)
+	negativeIDs = dedupeIDs(negativeIDs)
+	if len(positiveIDs) == 0 {
+		return nil, errors.New("at least one positive example id is required")
+	}
+
+	seeds := make(map[string]struct{}, len(positiveIDs)+len(negativeIDs))
+	for _, id := range positiveIDs {
+		seeds[id] = struct{}{}
+	}
+	for _, id := range negativeIDs {
+		if _, ok := seeds[id]; ok {
+			return nil, fmt.Errorf("id %s cannot be both a positive and a negative example", id)
+		}
+		seeds[id] = struct{}{}
+	}
+
+	if opts.Strategy != RecommendMean && opts.Strategy != RecommendRocchio && opts.Strategy != RecommendRRF {
+		return nil, fmt.Errorf("unknown recommend strategy %d", opts.Strategy)
+	}
+	if opts.RRFK < 0 {
+		return nil, errors.New("recommend rrf k cannot be negative")
+	}
+
+	topK := DefaultTopK
+	if opts.Query.TopK != nil {
+		topK = *opts.Query.TopK
+	}
+	if topK <= 0 || topK > MaxTopKAllowed {
+		return nil, fmt.Errorf("top_k must be between 1 and %d", MaxTopKAllowed)
+	}
+
+	// Over-fetch so excluding the seeds still leaves topK results
+	query := opts.Query
+	query.SparseIndices = nil
+	query.SparseValues = nil
+	fetchK := min(topK+len(seeds), MaxTopKAllowed)
+	query.TopK = &fetchK
+
+	// Validate the query with a stand-in vector before paying for the seed fetches
+	probe := query
+	probe.Vector = make([]float32, idx.Dimension)
+	for i := range probe.Vector {
+		probe.Vector[i] = 1
+	}
+	if _, err := idx.resolveQuery(probe); err != nil {
+		return nil, err
+	}
+
+	fetchIDs := append([]string{}, positiveIDs...)
+	if opts.Strategy != RecommendMean {
+		fetchIDs = append(fetchIDs, negativeIDs...)
+	}
+	vectors, err := idx.fetchSeedVectors(ctx, fetchIDs)
+	if err != nil {
+		return nil, err
+	}
+	positives, negatives := vectors[:len(positiveIDs)], vectors[len(positiveIDs):]
+
+	var results []QueryResult
+	switch opts.Strategy {
+	case RecommendMean:
+		query.Vector = combineCentroids(positives, nil, 1, 0)
+		results, err = idx.QueryWithOptions(ctx, query)
+	case RecommendRocchio:
+		beta, gamma := DefaultRocchioBeta, DefaultRocchioGamma
+		if opts.Beta != nil {
+			beta = *opts.Beta
+		}
+		if opts.Gamma != nil {
+			gamma = *opts.Gamma
+		}
+		query.Vector = combineCentroids(positives, negatives, beta, gamma)
+		results, err = idx.QueryWithOptions(ctx, query)
+	case RecommendRRF:
+		results, err = idx.recommendRRF(ctx, query, opts.RRFK, positives, negatives)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := results[:0]
+	for _, r := range results {
+		if _, seed := seeds[r.ID]; !seed {
+			filtered = append(filtered, r)
+		}
+	}
+	if len(filtered) > topK {
+		filtered = filtered[:topK]
+	}
+
+	return filtered, nil
+}
+
+// fetchSeedVectors retrieves the dense vector of every id, in order, with at most
+// DefaultGetConcurrency requests in flight.
+func (idx *Index) fetchSeedVectors(ctx context.Context, ids []string) ([][]float32, error) {
+	vectors := make([][]float32, len(ids))
+	errs := make([]error, len(ids))
+
+	next := runBounded(ctx, len(ids), DefaultGetConcurrency, func(i int) {
+		item, err := idx.GetVectorWithContext(ctx, ids[i])
+		if err != nil {
+			errs[i] = fmt.Errorf("failed to fetch example %s: %w", ids[i], err)
+
+			return
+		}
+		if len(item.Vector) == 0 {
+			errs[i] = fmt.Errorf("example %s has no dense vector", ids[i])
+
+			return
+		}
+		vectors[i] = item.Vector
+	})
+	if next < len(ids) {
+		return nil, fmt.Errorf("fetching examples cancelled: %w", ctx.Err())
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return vectors, nil
+}
+
+// recommendRRF runs one query per example concurrently and fuses the rankings with rank
+// constant k; zero selects DefaultRRFRankConstant.
+func (idx *Index) recommendRRF(ctx context.Context, query QueryOptions, k int, positives, negatives [][]float32) ([]QueryResult, error) {
+	examples := append(append([][]float32{}, positives...), negatives...)
+	lists := make([][]QueryResult, len(examples))
+	errs := make([]error, len(examples))
+
+	var wg sync.WaitGroup
+	for i, vector := range examples {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q := query
+			q.Vector = vector
+			lists[i], errs[i] = idx.QueryWithOptions(ctx, q)
+		}()
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	if k == 0 {
+		k = DefaultRRFRankConstant
+	}
+
+	scores := make(map[string]float64)
+	var fused []QueryResult
+	for i, results := range lists {
+		sign := 1.0
+		if i >= len(positives) {
+			sign = -1
+		}
+		for rank, r := range results {
+			if _, seen := scores[r.ID]; !seen {
+				if sign < 0 {
+					// Only penalize candidates that a positive example surfaced
+					continue
+				}
+				fused = append(fused, r)
+			}
+			scores[r.ID] += sign * rrfScore(rank, k)
+		}
+	}
+
+	sort.SliceStable(fused, func(a, b int) bool { return scores[fused[a].ID] > scores[fused[b].ID] })
+
+	return fused, nil
+}
+
+// combineCentroids returns beta*mean(positives) - gamma*mean(negatives).
+func combineCentroids(positives, negatives [][]float32, beta, gamma float64) []float32 {
+	out := make([]float32, len(positives[0]))
+	addScaled(out, positives, beta)
+	if len(negatives) > 0 {
+		addScaled(out, negatives, -gamma)
+	}
+
+	return out
+}
+
+// addScaled adds weight*mean(vectors) to out.
+func addScaled(out []float32, vectors [][]float32, weight float64) {
+	scale := float32(weight / float64(len(vectors)))
+	for _, v := range vectors {
+		for i := range out {
+			if i < len(v) {
+				out[i] += scale * v[i]
+			}
+		}
+	}
+}
+
+// dedupeIDs removes repeated and empty ids, keeping first occurrences in order.
+func dedupeIDs(ids []string) []string {
+	seen := make(map[string]struct{}, len(ids))
+	out := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+
+	return out
+}
@@ -0,0 +1,44 @@
+package endee
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRecommendValidatesBeforeFetchingSeeds(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	idx := &Index{Name: "docs", URL: srv.URL, Dimension: 2, SpaceType: Cosine}
+
+	tests := []struct {
+		name string
+		opts RecommendOptions
+	}{
+		{name: "top_k zero", opts: RecommendOptions{Query: NewQueryOptions(WithTopK(0))}},
+		{name: "top_k too large", opts: RecommendOptions{Query: NewQueryOptions(WithTopK(MaxTopKAllowed + 1))}},
+		{name: "ef too large", opts: RecommendOptions{Query: NewQueryOptions(WithEf(MaxEfSearchAllowed + 1))}},
+		{name: "filter params", opts: RecommendOptions{Query: NewQueryOptions(WithFilterParams(FilterParams{BoostPercentage: 500}))}},
+		{name: "unknown strategy", opts: RecommendOptions{Strategy: RecommendStrategy(99)}},
+		{name: "negative rrf k", opts: RecommendOptions{Strategy: RecommendRRF, RRFK: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := idx.Recommend(context.Background(), []string{"a", "b"}, []string{"c"}, tt.opts); err == nil {
+				t.Fatal("Recommend succeeded, want a validation error")
+			}
+		})
+	}
+
+	if requests.Load() != 0 {
+		t.Fatalf("server received %d requests, want 0", requests.Load())
+	}
+}
@@ -0,0 +1,295 @@
+package endee
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Defaults for the re-ranking stage.
+const (
+	DefaultRerankFetchMultiplier = 3               // Candidates fetched per requested result
+	DefaultRerankTimeout         = 2 * time.Second // Budget for one Rerank call
+	DefaultRerankTextField       = "text"          // Meta key HTTPReranker reads document text from
+)
+
+// RerankQuery is the query a Reranker scores candidates against.
+type RerankQuery struct {
+	Text   string    // Query text, set with WithQueryText
+	Vector []float32 // Dense query vector as given by the caller
+}
+
+// Reranker reorders and rescores query results, for example with a cross-encoder.
+// Implementations return the results best first with RerankScore set; they may drop results
+// but must not add new ones.
+type Reranker interface {
+	Rerank(ctx context.Context, query RerankQuery, results []QueryResult) ([]QueryResult, error)
+}
+
+// RerankOptions configures the re-ranking stage of a query.
+type RerankOptions struct {
+	Reranker        Reranker
+	FetchMultiplier int           // Candidates fetched per requested result (default DefaultRerankFetchMultiplier)
+	Timeout         time.Duration // Budget for the Rerank call (default DefaultRerankTimeout)
+	OnFallback      func(error)   // Called when re-ranking fails and the original order is kept
+}
+
+// WithReranker over-fetches candidates and reorders them with reranker.
+// A fetchMultiplier of zero selects DefaultRerankFetchMultiplier. If the reranker fails or
+// exceeds its timeout, the original order is returned.
+func WithReranker(reranker Reranker, fetchMultiplier int) QueryOption {
+	return func(o *QueryOptions) {
+		if o.Rerank == nil {
+			o.Rerank = &RerankOptions{}
+		}
+		o.Rerank.Reranker = reranker
+		o.Rerank.FetchMultiplier = fetchMultiplier
+	}
+}
+
+// WithRerankTimeout sets the time budget for the re-ranking stage.
+func WithRerankTimeout(timeout time.Duration) QueryOption {
+	return func(o *QueryOptions) {
+		if o.Rerank == nil {
+			o.Rerank = &RerankOptions{}
+		}
+		o.Rerank.Timeout = timeout
+	}
+}
+
+// WithQueryText sets the query text passed to the reranker.
+func WithQueryText(text string) QueryOption {
+	return func(o *QueryOptions) { o.QueryText = text }
+}
+
+// validate checks the re-ranking parameters.
+func (r *RerankOptions) validate() error {
+	if r.Reranker == nil {
+		return errors.New("reranker cannot be nil")
+	}
+	if r.FetchMultiplier < 0 {
+		return errors.New("rerank fetch multiplier cannot be negative")
+	}
+	if r.Timeout < 0 {
+		return errors.New("rerank timeout cannot be negative")
+	}
+
+	return nil
+}
+
+// applyRerank reorders candidates and truncates them to k, keeping the original order
+// when the reranker fails, times out or returns results that were not candidates.
+func applyRerank(ctx context.Context, opts RerankOptions, query RerankQuery, candidates []QueryResult, k int) []QueryResult {
+	original := candidates
+	if len(original) > k {
+		original = original[:k]
+	}
+	if len(candidates) == 0 {
+		return candidates
+	}
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultRerankTimeout
+	}
+	rerankCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Hand the reranker a copy so a failed attempt cannot disturb the fallback order
+	input := make([]QueryResult, len(candidates))
+	copy(input, candidates)
+
+	reranked, err := opts.Reranker.Rerank(rerankCtx, query, input)
+	if err == nil {
+		err = checkReranked(candidates, reranked)
+	}
+	if err != nil {
+		if opts.OnFallback != nil {
+			opts.OnFallback(err)
+		}
+
+		return original
+	}
+
+	if len(reranked) > k {
+		reranked = reranked[:k]
+	}
+
+	return reranked
+}
+
+// checkReranked rejects reranker output containing results that were not candidates.
+func checkReranked(candidates, reranked []QueryResult) error {
+	ids := make(map[string]struct{}, len(candidates))
+	for _, c := range candidates {
+		ids[c.ID] = struct{}{}
+	}
+	for _, r := range reranked {
+		if _, ok := ids[r.ID]; !ok {
+			return fmt.Errorf("reranker returned unknown result %s", r.ID)
+		}
+	}
+
+	return nil
+}
+
+// RerankFunc scores a single result locally; higher scores rank first.
+// As a Reranker it scores every candidate concurrently and sorts by score.
+type RerankFunc func(ctx context.Context, query RerankQuery, result QueryResult) (float64, error)
+
+// Rerank implements Reranker.
+func (f RerankFunc) Rerank(ctx context.Context, query RerankQuery, results []QueryResult) ([]QueryResult, error) {
+	scores := make([]float64, len(results))
+	errs := make([]error, len(results))
+
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			scores[i], errs[i] = f(ctx, query, results[i])
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("rerank cancelled: %w", ctx.Err())
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return sortByRerankScore(results, scores), nil
+}
+
+// HTTPReranker calls a re-ranking service over HTTP.
+//
+// It POSTs {"model", "query", "documents", "top_n"} and expects
+// {"results": [{"index", "relevance_score"}]}, the shape used by common cross-encoder
+// servers. Document text is read from each result's Meta[TextField].
+type HTTPReranker struct {
+	URL       string       // Endpoint receiving the rerank request
+	Token     string       // Sent as a bearer token when set
+	Model     string       // Optional model name
+	TextField string       // Meta key holding document text (default DefaultRerankTextField)
+	HTTP      *http.Client // Client to use (default http.DefaultClient)
+}
+
+// httpRerankRequest is the body sent by HTTPReranker.
+type httpRerankRequest struct {
+	Model     string   `json:"model,omitempty"`
+	Query     string   `json:"query"`
+	Documents []string `json:"documents"`
+	TopN      int      `json:"top_n"`
+}
+
+// httpRerankResponse is the body HTTPReranker expects back.
+type httpRerankResponse struct {
+	Results []struct {
+		Index          int     `json:"index"`
+		RelevanceScore float64 `json:"relevance_score"`
+	} `json:"results"`
+}
+
+// Rerank implements Reranker.
+func (h *HTTPReranker) Rerank(ctx context.Context, query RerankQuery, results []QueryResult) ([]QueryResult, error) {
+	if query.Text == "" {
+		return nil, errors.New("http reranker requires query text; set it with WithQueryText")
+	}
+
+	field := h.TextField
+	if field == "" {
+		field = DefaultRerankTextField
+	}
+
+	documents := make([]string, len(results))
+	for i, r := range results {
+		if text, ok := r.Meta[field].(string); ok {
+			documents[i] = text
+		}
+	}
+
+	body, err := json.Marshal(httpRerankRequest{
+		Model:     h.Model,
+		Query:     query.Text,
+		Documents: documents,
+		TopN:      len(documents),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal rerank request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rerank request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.Token)
+	}
+
+	client := h.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute rerank request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+		return nil, fmt.Errorf("reranker returned status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	var parsed httpRerankResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode rerank response: %w", err)
+	}
+
+	reranked := make([]QueryResult, 0, len(parsed.Results))
+	seen := make(map[int]struct{}, len(parsed.Results))
+	for _, r := range parsed.Results {
+		if r.Index < 0 || r.Index >= len(results) {
+			return nil, fmt.Errorf("reranker returned out-of-range index %d", r.Index)
+		}
+		if _, dup := seen[r.Index]; dup {
+			continue
+		}
+		seen[r.Index] = struct{}{}
+
+		result := results[r.Index]
+		result.RerankScore = float32(r.RelevanceScore)
+		reranked = append(reranked, result)
+	}
+
+	sort.SliceStable(reranked, func(a, b int) bool { return reranked[a].RerankScore > reranked[b].RerankScore })
+
+	return reranked, nil
+}
+
+// sortByRerankScore sets RerankScore on each result and sorts them best first.
+func sortByRerankScore(results []QueryResult, scores []float64) []QueryResult {
+	for i := range results {
+		results[i].RerankScore = float32(scores[i])
+	}
+	sort.SliceStable(results, func(a, b int) bool { return results[a].RerankScore > results[b].RerankScore })
+
+	return results
+}
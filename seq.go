@@ -0,0 +1,269 @@
+package endee
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// UpsertSummary reports the outcome of UpsertSeq or UpsertChan.
+type UpsertSummary struct {
+	Count         int // Vectors acknowledged by the server
+	Batches       int // Requests sent, successful or not
+	FailedBatches int // Requests that failed after retries
+	FailedVectors int // Vectors rejected by validation or contained in failed batches
+}
+
+// seqUpserter accumulates a lazily produced stream into batches and sends them.
+type seqUpserter struct {
+	idx      *Index
+	ctx      context.Context
+	cfg      *upsertConfig
+	batchCfg AdaptiveBatchConfig
+	limiter  *aimdLimiter
+
+	batch   encodedBatch
+	batchID map[string]struct{}
+
+	// inflight maps each ID in a sent but unfinished batch to that batch's done channel
+	inflight map[string]chan struct{}
+
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	summary  UpsertSummary
+	firstErr error
+}
+
+// UpsertSeq upserts every item produced by seq, batching lazily by encoded size and item count.
+//
+// Items are pulled only when a request slot is free, so a slow server applies backpressure
+// to the producer. Invalid items and failed batches are counted in the summary without
+// stopping the stream; the first such error is returned once seq is exhausted. Cancelling
+// ctx stops pulling from seq, waits for in-flight requests and returns ctx's error.
+// An ID repeated within the current batch starts a new batch, and a batch holding an ID
+// whose earlier copy is still in flight waits for that request first, so the last item
+// produced for an ID is the one applied. WithDryRun and WithStreaming are rejected
+// before seq is read.
+func (idx *Index) UpsertSeq(ctx context.Context, seq iter.Seq[VectorItem], opts ...UpsertOption) (UpsertSummary, error) {
+	cfg := newUpsertConfig(opts)
+	if err := cfg.rejectDryRunAndStreaming("UpsertSeq and UpsertChan"); err != nil {
+		return UpsertSummary{}, err
+	}
+	cfg.progress = newProgressTracker(cfg.progressFn, 0)
+	defer cfg.progress.finish()
+
+	batchCfg := cfg.adaptive.withDefaults()
+	u := &seqUpserter{
+		idx:      idx,
+		ctx:      ctx,
+		cfg:      cfg,
+		batchCfg: batchCfg,
+		limiter:  idx.concurrencyLimiter(batchCfg),
+		batchID:  make(map[string]struct{}),
+		inflight: make(map[string]chan struct{}),
+	}
+	defer idx.rememberLimit(u.limiter)
+
+	var stopErr error
+	for item := range seq {
+		if err := ctx.Err(); err != nil {
+			// The item just pulled is dropped along with the rest of the stream
+			u.recordFailure(1, nil)
+			stopErr = fmt.Errorf("upsert cancelled: %w", err)
+
+			break
+		}
+		if err := u.add(item); err != nil {
+			stopErr = err
+
+			break
+		}
+	}
+	if stopErr == nil {
+		stopErr = u.flush()
+	} else if len(u.batch.items) > 0 {
+		// The partially filled batch was never sent
+		u.recordFailure(len(u.batch.items), nil)
+	}
+
+	u.wg.Wait()
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if stopErr != nil {
+		return u.summary, stopErr
+	}
+	if u.firstErr != nil {
+		return u.summary, fmt.Errorf("upsert failed: %d vectors failed: %w", u.summary.FailedVectors, u.firstErr)
+	}
+
+	return u.summary, nil
+}
+
+// UpsertChan upserts every item received from ch until it is closed or ctx is cancelled.
+// It behaves like UpsertSeq.
+func (idx *Index) UpsertChan(ctx context.Context, ch <-chan VectorItem, opts ...UpsertOption) (UpsertSummary, error) {
+	seq := func(yield func(VectorItem) bool) {
+		for {
+			select {
+			case item, ok := <-ch:
+				if !ok || !yield(item) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+
+	summary, err := idx.UpsertSeq(ctx, seq, opts...)
+	if err == nil && ctx.Err() != nil {
+		err = fmt.Errorf("upsert cancelled: %w", ctx.Err())
+	}
+
+	return summary, err
+}
+
+// add validates and encodes item, sending the current batch first when item does not fit.
+// It returns an error only when the stream must stop.
+func (u *seqUpserter) add(item VectorItem) error {
+	if issues := u.idx.collectUpsertIssues([]VectorItem{item}, true); len(issues) > 0 {
+		u.recordFailure(1, issues[0])
+
+		return nil
+	}
+
+	tuple, err := u.idx.buildVectorTuple(item)
+	if err != nil {
+		u.recordFailure(1, fmt.Errorf("%w (item id: %s)", err, item.ID))
+
+		return nil
+	}
+
+	encoded, err := msgpack.Marshal(&tuple)
+	if err != nil {
+		u.recordFailure(1, fmt.Errorf("failed to serialize vector (item id: %s): %w", item.ID, err))
+
+		return nil
+	}
+
+	return u.append(item.ID, encoded)
+}
+
+// append adds an encoded item to the current batch, flushing as needed.
+func (u *seqUpserter) append(id string, encoded []byte) error {
+	_, duplicate := u.batchID[id]
+	full := len(u.batch.items) >= u.batchCfg.MaxBatchItems ||
+		(len(u.batch.items) > 0 && u.batch.size+len(encoded) > u.batchCfg.MaxBatchBytes)
+
+	if duplicate || full {
+		if err := u.flush(); err != nil {
+			return err
+		}
+	}
+
+	u.batch.items = append(u.batch.items, encoded)
+	u.batch.size += len(encoded)
+	u.batchID[id] = struct{}{}
+
+	return nil
+}
+
+// flush sends the current batch once a request slot is free.
+func (u *seqUpserter) flush() error {
+	if len(u.batch.items) == 0 {
+		return nil
+	}
+
+	batch := u.batch
+	ids := u.batchID
+	u.batch = encodedBatch{}
+	u.batchID = make(map[string]struct{})
+
+	if err := u.waitForEarlierCopies(ids); err != nil {
+		u.recordFailure(len(batch.items), nil)
+
+		return err
+	}
+
+	// Blocks while the limiter is saturated, holding back the producer
+	if err := u.limiter.acquire(u.ctx); err != nil {
+		u.recordFailure(len(batch.items), nil)
+
+		return err
+	}
+
+	done := make(chan struct{})
+
+	u.mu.Lock()
+	u.summary.Batches++
+	for id := range ids {
+		u.inflight[id] = done
+	}
+	u.mu.Unlock()
+
+	u.wg.Add(1)
+	go func() {
+		defer u.wg.Done()
+		defer close(done)
+
+		err := u.idx.sendAdaptiveBatch(u.ctx, batch, u.limiter, u.batchCfg, u.cfg)
+
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		for id := range ids {
+			if u.inflight[id] == done {
+				delete(u.inflight, id)
+			}
+		}
+		if err != nil {
+			u.summary.FailedBatches++
+			u.summary.FailedVectors += len(batch.items)
+			if u.firstErr == nil {
+				u.firstErr = err
+			}
+
+			return
+		}
+		u.summary.Count += len(batch.items)
+	}()
+
+	return nil
+}
+
+// waitForEarlierCopies blocks until no batch in flight holds any of ids, or ctx is done.
+func (u *seqUpserter) waitForEarlierCopies(ids map[string]struct{}) error {
+	for id := range ids {
+		u.mu.Lock()
+		done := u.inflight[id]
+		u.mu.Unlock()
+
+		if done == nil {
+			continue
+		}
+		select {
+		case <-done:
+		case <-u.ctx.Done():
+			return fmt.Errorf("upsert cancelled: %w", u.ctx.Err())
+		}
+	}
+
+	return nil
+}
+
+// recordFailure counts n vectors that were not sent. A nil err only updates the counters.
+func (u *seqUpserter) recordFailure(n int, err error) {
+	u.cfg.progress.failed(n)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.summary.FailedVectors += n
+	if err != nil && u.firstErr == nil {
+		u.firstErr = err
+	}
+}
@@ -0,0 +1,112 @@
+package endee
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+func TestUpsertSeqRejectsDryRunAndStreaming(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer srv.Close()
+
+	idx := &Index{Name: "docs", URL: srv.URL, Dimension: 2, SpaceType: L2}
+	items := []VectorItem{{ID: "a", Vector: []float32{1, 1}}, {ID: "b", Vector: []float32{2, 1}}}
+
+	for name, opt := range map[string]UpsertOption{
+		"dry run":   WithDryRun(nil),
+		"streaming": WithStreaming(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			pulled := 0
+			seq := func(yield func(VectorItem) bool) {
+				for _, item := range items {
+					pulled++
+					if !yield(item) {
+						return
+					}
+				}
+			}
+			if _, err := idx.UpsertSeq(context.Background(), seq, opt); err == nil {
+				t.Fatal("UpsertSeq succeeded, want the option rejected")
+			}
+			if pulled != 0 {
+				t.Fatalf("UpsertSeq pulled %d items, want 0", pulled)
+			}
+
+			ch := make(chan VectorItem, len(items))
+			for _, item := range items {
+				ch <- item
+			}
+			close(ch)
+			if _, err := idx.UpsertChan(context.Background(), ch, opt); err == nil {
+				t.Fatal("UpsertChan succeeded, want the option rejected")
+			}
+		})
+	}
+
+	if requests.Load() != 0 {
+		t.Fatalf("server received %d requests, want 0", requests.Load())
+	}
+}
+
+func TestUpsertSeqAppliesLatestCopyOfAnID(t *testing.T) {
+	var mu sync.Mutex
+	var applied []float32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var tuples []vectorTuple
+		if err := msgpack.Unmarshal(body, &tuples); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+		for _, tuple := range tuples {
+			// Hold the first copy back so a racing second copy would land first
+			if tuple.ID == "x" && tuple.Vector[0] == 1 {
+				time.Sleep(100 * time.Millisecond)
+			}
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, tuple := range tuples {
+			if tuple.ID == "x" {
+				applied = append(applied, tuple.Vector[0])
+			}
+		}
+	}))
+	defer srv.Close()
+
+	idx := &Index{Name: "docs", URL: srv.URL, Dimension: 2, SpaceType: L2}
+
+	items := []VectorItem{{ID: "x", Vector: []float32{1, 1}}}
+	for i := 0; i < 5; i++ {
+		items = append(items, VectorItem{ID: "v" + strconv.Itoa(i), Vector: []float32{3, 3}})
+	}
+	items = append(items, VectorItem{ID: "x", Vector: []float32{2, 2}})
+
+	summary, err := idx.UpsertSeq(context.Background(), slices.Values(items),
+		WithAdaptiveBatching(AdaptiveBatchConfig{MinConcurrency: 4, MaxConcurrency: 4, MaxBatchItems: 2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Count != len(items) {
+		t.Fatalf("Count = %d, want %d", summary.Count, len(items))
+	}
+	if !slices.Equal(applied, []float32{1, 2}) {
+		t.Fatalf("copies of x applied in order %v, want [1 2]", applied)
+	}
+}
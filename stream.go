@@ -0,0 +1,161 @@
+package endee
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// streamBufferSize is the write buffer placed between the msgpack encoder and the request pipe.
+const streamBufferSize = 32 * 1024
+
+// WithStreaming encodes the upsert straight into the request body through an io.Pipe,
+// normalizing vectors and compressing metadata on the fly. Only one item's metadata is
+// held in encoded form at a time, instead of a normalized copy of the whole batch plus
+// its serialized bytes. The batch is sent as a single chunked request.
+func WithStreaming() UpsertOption {
+	return func(cfg *upsertConfig) {
+		cfg.streaming = true
+	}
+}
+
+// upsertStreaming sends inputArray as one streamed request, re-encoding it for each retry.
+func (idx *Index) upsertStreaming(ctx context.Context, inputArray []VectorItem, cfg *upsertConfig) error {
+	maxRetries := cfg.adaptive.withDefaults().MaxRetries
+
+	for attempt := 0; ; attempt++ {
+		written, err := idx.sendStreamingBatch(ctx, inputArray)
+		if attempt == 0 {
+			cfg.progress.submitted(len(inputArray), int(written))
+		} else {
+			cfg.progress.sent(int(written))
+		}
+
+		if err == nil {
+			cfg.progress.acknowledged(len(inputArray))
+
+			return nil
+		}
+		if !isRetryableError(err) || attempt >= maxRetries {
+			cfg.progress.failed(len(inputArray))
+
+			return err
+		}
+
+		cfg.progress.retried(len(inputArray))
+		if err := sleepContext(ctx, retryBackoff(attempt)); err != nil {
+			cfg.progress.failed(len(inputArray))
+
+			return err
+		}
+	}
+}
+
+// sendStreamingBatch posts inputArray with a body produced by a background encoder.
+// It returns the number of body bytes written.
+func (idx *Index) sendStreamingBatch(ctx context.Context, inputArray []VectorItem) (int64, error) {
+	defer idx.invalidateCache()
+
+	var written atomic.Int64
+
+	newBody := func() io.ReadCloser {
+		written.Store(0)
+		pr, pw := io.Pipe()
+		go func() {
+			counter := &countingWriter{w: pw, n: &written}
+			_ = pw.CloseWithError(idx.encodeVectorBatch(counter, inputArray))
+		}()
+
+		return pr
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", idx.buildURL("index/%s/vector/insert"), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Body = newBody()
+	req.GetBody = func() (io.ReadCloser, error) {
+		return newBody(), nil
+	}
+
+	resp, err := idx.doRequest(ctx, req, "application/msgpack")
+	if err != nil {
+		return written.Load(), err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	return written.Load(), checkError(resp)
+}
+
+// encodeVectorBatch writes inputArray to w as a msgpack array of wire tuples.
+func (idx *Index) encodeVectorBatch(w io.Writer, inputArray []VectorItem) error {
+	bw := bufio.NewWriterSize(w, streamBufferSize)
+	enc := msgpack.NewEncoder(bw)
+
+	if err := enc.EncodeArrayLen(len(inputArray)); err != nil {
+		return fmt.Errorf("failed to encode batch header: %w", err)
+	}
+	for i := range inputArray {
+		if err := idx.encodeVectorItem(enc, &inputArray[i]); err != nil {
+			return err
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("failed to flush vector batch: %w", err)
+	}
+
+	return nil
+}
+
+// encodeVectorItem writes the item's wire tuple straight to enc, normalizing each element as it is written.
+func (idx *Index) encodeVectorItem(enc *msgpack.Encoder, item *VectorItem) error {
+	tuple, err := idx.buildVectorTuple(*item)
+	if err != nil {
+		return err
+	}
+
+	return tuple.EncodeMsgpack(enc)
+}
+
+// vectorNorm returns the value normalizeVector divides by: the L2 norm for cosine
+// indexes with a non-zero vector, and 1 otherwise.
+func (idx *Index) vectorNorm(vector []float32) float32 {
+	if idx.SpaceType != "cosine" {
+		return 1.0
+	}
+
+	var sum float32
+	for _, v := range vector {
+		sum += v * v
+	}
+	norm := float32(math.Sqrt(float64(sum)))
+	if norm == 0 {
+		return 1.0
+	}
+
+	return norm
+}
+
+// countingWriter counts bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n *atomic.Int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n.Add(int64(n))
+
+	if err != nil {
+		return n, fmt.Errorf("failed to write request body: %w", err)
+	}
+
+	return n, nil
+}
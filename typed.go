@@ -0,0 +1,226 @@
+package endee
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// TypedVector is a VectorItem whose Meta and Filter are carried by a struct.
+//
+// Data's fields are keyed by their json tag names. Fields also tagged endee:"filter"
+// are stored in the vector's Filter; all others are stored in its Meta.
+type TypedVector[T any] struct {
+	ID            string
+	Vector        []float32
+	SparseIndices []int
+	SparseValues  []float32
+	Data          T
+}
+
+// TypedResult is a QueryResult with Meta and Filter decoded into T.
+// Err reports a decoding failure for this result only; QueryResult is still populated.
+type TypedResult[T any] struct {
+	QueryResult
+	Data T
+	Err  error
+}
+
+// QueryTyped runs QueryWithOptions and decodes every result's Meta and Filter into T.
+func QueryTyped[T any](ctx context.Context, idx *Index, opts QueryOptions) ([]TypedResult[T], error) {
+	if _, err := typedFilterKeys(reflect.TypeFor[T]()); err != nil {
+		return nil, err
+	}
+
+	results, err := idx.QueryWithOptions(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	typed := make([]TypedResult[T], len(results))
+	for i, r := range results {
+		typed[i].QueryResult = r
+		if err := decodeTyped(r.Meta, r.Filter, &typed[i].Data); err != nil {
+			typed[i].Err = fmt.Errorf("failed to decode result %s: %w", r.ID, err)
+		}
+	}
+
+	return typed, nil
+}
+
+// GetVectorTyped retrieves a vector by ID and decodes its Meta and Filter into T.
+func GetVectorTyped[T any](ctx context.Context, idx *Index, id string) (TypedVector[T], error) {
+	item, err := idx.GetVectorWithContext(ctx, id)
+	if err != nil {
+		return TypedVector[T]{}, err
+	}
+
+	typed := TypedVector[T]{
+		ID:            item.ID,
+		Vector:        item.Vector,
+		SparseIndices: item.SparseIndices,
+		SparseValues:  item.SparseValues,
+	}
+	if err := decodeTyped(item.Meta, item.Filter, &typed.Data); err != nil {
+		return typed, fmt.Errorf("failed to decode vector %s: %w", id, err)
+	}
+
+	return typed, nil
+}
+
+// UpsertTyped encodes each item's Data into Meta and Filter and upserts the batch.
+func UpsertTyped[T any](ctx context.Context, idx *Index, items []TypedVector[T], opts ...UpsertOption) error {
+	vectors := make([]VectorItem, len(items))
+	for i, item := range items {
+		meta, filter, err := encodeTyped(item.Data)
+		if err != nil {
+			return fmt.Errorf("failed to encode item %s: %w", item.ID, err)
+		}
+
+		vectors[i] = VectorItem{
+			ID:            item.ID,
+			Vector:        item.Vector,
+			SparseIndices: item.SparseIndices,
+			SparseValues:  item.SparseValues,
+			Meta:          meta,
+			Filter:        filter,
+		}
+	}
+
+	return idx.UpsertWithContext(ctx, vectors, opts...)
+}
+
+// encodeTyped splits data's JSON form into meta and filter maps.
+func encodeTyped(data any) (map[string]interface{}, map[string]interface{}, error) {
+	filterKeys, err := typedFilterKeys(reflect.TypeOf(data))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal data: %w", err)
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, nil, fmt.Errorf("data must encode to a JSON object: %w", err)
+	}
+
+	meta := make(map[string]interface{})
+	var filter map[string]interface{}
+	for key, value := range fields {
+		if _, ok := filterKeys[key]; ok {
+			if filter == nil {
+				filter = make(map[string]interface{})
+			}
+			filter[key] = value
+
+			continue
+		}
+		meta[key] = value
+	}
+
+	return meta, filter, nil
+}
+
+// decodeTyped fills dst from meta and filter, taking filter-tagged fields from filter only.
+func decodeTyped(meta, filter map[string]interface{}, dst any) error {
+	filterKeys, err := typedFilterKeys(reflect.TypeOf(dst).Elem())
+	if err != nil {
+		return err
+	}
+
+	fields := make(map[string]interface{}, len(meta)+len(filter))
+	for key, value := range meta {
+		if _, ok := filterKeys[key]; !ok {
+			fields[key] = value
+		}
+	}
+	for key, value := range filter {
+		if _, ok := filterKeys[key]; ok {
+			fields[key] = value
+		}
+	}
+
+	// Round-trip through JSON so numbers land in the field's declared type
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return fmt.Errorf("failed to marshal fields: %w", err)
+	}
+	if err := json.Unmarshal(raw, dst); err != nil {
+		return fmt.Errorf("failed to unmarshal fields: %w", err)
+	}
+
+	return nil
+}
+
+// typedKeysCache maps a struct type to the JSON keys of its endee:"filter" fields.
+var typedKeysCache sync.Map
+
+// typedFilterKeys returns the JSON keys of t's fields tagged endee:"filter".
+func typedFilterKeys(t reflect.Type) (map[string]struct{}, error) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("typed data must be a struct, got %v", t)
+	}
+
+	if cached, ok := typedKeysCache.Load(t); ok {
+		return cached.(map[string]struct{}), nil
+	}
+
+	keys := make(map[string]struct{})
+	collectFilterKeys(t, keys)
+	typedKeysCache.Store(t, keys)
+
+	return keys, nil
+}
+
+// collectFilterKeys walks t's fields, descending into embedded structs the way encoding/json does.
+func collectFilterKeys(t reflect.Type, keys map[string]struct{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				collectFilterKeys(embedded, keys)
+
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Tag.Get("endee") == "filter" {
+			if name == "" {
+				name = field.Name
+			}
+			keys[name] = struct{}{}
+		}
+	}
+}
+
+// jsonFieldName returns the name from field's json tag and whether the field is excluded.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ := strings.Cut(tag, ",")
+
+	return name, false
+}
@@ -0,0 +1,136 @@
+package endee
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// UpsertIssue describes one problem found while validating an upsert batch.
+type UpsertIssue struct {
+	Item    int    // Position of the item in the batch, or -1 for batch-level issues
+	ID      string // ID of the offending item, if any
+	Message string
+}
+
+func (e UpsertIssue) Error() string {
+	return e.Message
+}
+
+// UpsertItemReport holds the measured sizes and issues of a single item.
+type UpsertItemReport struct {
+	ID           string
+	EncodedBytes int      // Size of the item's msgpack wire tuple, 0 if it could not be encoded
+	MetaBytes    int      // Size of the zlib-compressed metadata
+	Issues       []string // Messages of the issues found for this item
+}
+
+// UpsertValidationReport is the result of validating an upsert batch without sending it.
+type UpsertValidationReport struct {
+	Items             []UpsertItemReport
+	Issues            []UpsertIssue
+	TotalEncodedBytes int
+	TotalMetaBytes    int
+}
+
+// Valid reports whether the batch passed every check.
+func (r *UpsertValidationReport) Valid() bool {
+	return len(r.Issues) == 0
+}
+
+// Err returns nil for a valid batch, otherwise an *UpsertValidationError listing every issue.
+func (r *UpsertValidationReport) Err() error {
+	if r.Valid() {
+		return nil
+	}
+
+	return &UpsertValidationError{Issues: r.Issues}
+}
+
+// UpsertValidationError reports every issue found in a rejected batch.
+type UpsertValidationError struct {
+	Issues []UpsertIssue
+}
+
+func (e *UpsertValidationError) Error() string {
+	if len(e.Issues) == 1 {
+		return fmt.Sprintf("Validation Error: %s", e.Issues[0].Message)
+	}
+
+	messages := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		messages[i] = issue.Message
+	}
+
+	return fmt.Sprintf("Validation Error: %d issues: %s", len(e.Issues), strings.Join(messages, "; "))
+}
+
+// ValidateUpsert runs every client-side upsert check against the index and measures each item's
+// encoded size, without contacting the server. Unlike Upsert it reports all issues, not just the first.
+func (idx *Index) ValidateUpsert(inputArray []VectorItem) *UpsertValidationReport {
+	report := &UpsertValidationReport{
+		Items: make([]UpsertItemReport, len(inputArray)),
+	}
+
+	if len(inputArray) > MaxVectorsPerBatch {
+		report.Issues = append(report.Issues, UpsertIssue{
+			Item:    -1,
+			Message: fmt.Sprintf("cannot insert more than %d vectors at a time", MaxVectorsPerBatch),
+		})
+	}
+
+	issues := idx.collectUpsertIssues(inputArray, false)
+	report.Issues = append(report.Issues, issues...)
+	for _, issue := range issues {
+		if issue.Item >= 0 {
+			report.Items[issue.Item].Issues = append(report.Items[issue.Item].Issues, issue.Message)
+		}
+	}
+
+	for i, item := range inputArray {
+		itemReport := &report.Items[i]
+		itemReport.ID = item.ID
+
+		// Items with the wrong dimension cannot be normalized; that issue is already reported,
+		// so only their metadata is measured
+		if len(item.Vector) != idx.Dimension {
+			if metaBytes, err := JSONZip(item.Meta); err == nil {
+				itemReport.MetaBytes = len(metaBytes)
+			} else {
+				report.addItemIssue(i, item.ID, fmt.Sprintf("failed to compress metadata: %v (item id: %s)", err, item.ID))
+			}
+
+			continue
+		}
+
+		// The tuple holds the compressed metadata, so it is measured from there
+		tuple, err := idx.buildVectorTuple(item)
+		if err != nil {
+			report.addItemIssue(i, item.ID, fmt.Sprintf("%v (item id: %s)", err, item.ID))
+
+			continue
+		}
+		itemReport.MetaBytes = len(tuple.Meta)
+		encoded, err := msgpack.Marshal(&tuple)
+		if err != nil {
+			report.addItemIssue(i, item.ID, fmt.Sprintf("failed to serialize vector: %v (item id: %s)", err, item.ID))
+
+			continue
+		}
+		itemReport.EncodedBytes = len(encoded)
+	}
+
+	for _, item := range report.Items {
+		report.TotalEncodedBytes += item.EncodedBytes
+		report.TotalMetaBytes += item.MetaBytes
+	}
+
+	return report
+}
+
+// addItemIssue records an issue against both the report and the item.
+func (r *UpsertValidationReport) addItemIssue(i int, id, message string) {
+	r.Issues = append(r.Issues, UpsertIssue{Item: i, ID: id, Message: message})
+	r.Items[i].Issues = append(r.Items[i].Issues, message)
+}